@@ -1,11 +1,15 @@
 package main
 
 import (
+	"context"
 	"flight-aggregator/internal/config"
 	"flight-aggregator/internal/controller"
 	"flight-aggregator/internal/middleware"
+	"flight-aggregator/internal/models"
+	"flight-aggregator/internal/repository"
 	"flight-aggregator/internal/service"
 	"flight-aggregator/internal/usecase"
+	"flight-aggregator/internal/utils"
 	"log"
 
 	"github.com/labstack/echo/v4"
@@ -14,16 +18,48 @@ import (
 
 func main() {
 	e := echo.New()
+	// Handlers and middleware return typed errors, rendered here as ErrorResponse
+	e.HTTPErrorHandler = middleware.ErrorHandler(utils.NewLogger())
 
 	// Global Middleware
 	e.Use(echo_middleware.Logger())
 	e.Use(echo_middleware.Recover())
 	e.Use(echo_middleware.CORS())
 
+	cfg := config.MustLoad()
+	plans, err := config.LoadPlans(cfg.PlansFile)
+	if err != nil {
+		log.Fatalf("Failed to load plans: %v", err)
+	}
+	rdb := repository.NewRedisClient(cfg.RedisAddr)
+	apiKeyRepository := repository.NewRedisAPIKeyRepository(rdb)
+	tenantRepository := repository.NewRedisTenantRepository(rdb)
+	promoRepository := repository.NewRedisPromoRepository(rdb)
+	searchRepository := repository.NewRedisSearchRepository(rdb)
+	quotaUsecase := usecase.NewQuotaUsecase(repository.NewRedisQuotaRepository(rdb), repository.QuotaLimits{
+		Daily:   cfg.AnonSearchesPerDay,
+		Monthly: cfg.AnonSearchesPerMonth,
+	})
+
+	pricingEngine, err := usecase.NewPricingEngine(func() ([]models.PricingRule, error) {
+		return config.LoadPricingRules(cfg.PricingRulesFile)
+	})
+	if err != nil {
+		log.Fatalf("Failed to load pricing rules: %v", err)
+	}
+	if cfg.PricingRulesFile != "" && cfg.PricingReloadInterval > 0 {
+		go pricingEngine.WatchRules(context.Background(), cfg.PricingReloadInterval)
+	}
+
 	// Initialize layers
 	flightService := service.NewFlightService()
-	flightUsecase := usecase.NewFlightUsecase(flightService)
+	flightUsecase := usecase.NewFlightUsecase(flightService, pricingEngine, promoRepository, searchRepository)
 	flightController := controller.NewFlightController(flightUsecase)
+	apiKeyController := controller.NewAPIKeyController(usecase.NewAPIKeyUsecase(apiKeyRepository, tenantRepository, plans))
+	tenantController := controller.NewTenantController(usecase.NewTenantUsecase(tenantRepository))
+	pricingController := controller.NewPricingController(pricingEngine)
+	promoController := controller.NewPromoController(usecase.NewPromoUsecase(promoRepository))
+	usageController := controller.NewUsageController(quotaUsecase)
 	
 	if flightController == nil {
 		log.Fatal("Failed to initialize flight controller")
@@ -38,18 +74,39 @@ func main() {
 	// API routes with middleware
 	api := e.Group("/api")
 	api.Use(middleware.TracerMiddleware())
-	api.Use(middleware.NewRedisSlidingWindowRateLimit())
+	api.Use(middleware.APIKeyAuth(apiKeyRepository, plans, cfg.APIKeyRequired, cfg.RateLimitFailureMode))
+	api.Use(middleware.TenantResolver(tenantRepository, cfg.RateLimitFailureMode))
+	api.Use(middleware.NewRedisSlidingWindowRateLimit(rdb))
 	
-	api.POST("/flights/search", flightController.SearchFlights)
+	api.POST("/flights/search", flightController.SearchFlights, middleware.DebugAccess(cfg.AdminAPIKey), middleware.SearchQuota(quotaUsecase))
 	api.GET("/flights/filters", flightController.GetFilters)
+	api.GET("/searches/:id", flightController.GetSearch, middleware.DebugAccess(cfg.AdminAPIKey))
+	api.GET("/usage", usageController.GetUsage)
+
+	// Key management, protected by the admin key
+	admin := e.Group("/admin")
+	admin.Use(middleware.TracerMiddleware())
+	admin.Use(middleware.AdminAuth(cfg.AdminAPIKey))
+	admin.POST("/keys", apiKeyController.CreateKey)
+	admin.GET("/keys", apiKeyController.ListKeys)
+	admin.DELETE("/keys/:id", apiKeyController.RevokeKey)
+	admin.GET("/plans", apiKeyController.ListPlans)
+	admin.PUT("/tenants/:id", tenantController.SaveTenant)
+	admin.GET("/tenants", tenantController.ListTenants)
+	admin.GET("/tenants/:id", tenantController.GetTenant)
+	admin.DELETE("/tenants/:id", tenantController.DeleteTenant)
+	admin.GET("/pricing/rules", pricingController.ListRules)
+	admin.POST("/pricing/reload", pricingController.ReloadRules)
+	admin.PUT("/promos/:code", promoController.SavePromo)
+	admin.GET("/promos", promoController.ListPromos)
+	admin.DELETE("/promos/:code", promoController.DeletePromo)
 	
 	// Health check with tracer only
 	health := e.Group("/health")
 	health.Use(middleware.TracerMiddleware())
 	health.GET("", flightController.HealthCheck)
 
-	// Load config and start server
-	cfg := config.MustLoad()
+	// Start server
 	port := ":" + cfg.Port
 	log.Println("Starting flight aggregator server on", port)
 	log.Printf("Rate limit: %d requests per %v", cfg.RateLimitCount, cfg.RateLimitWindow)
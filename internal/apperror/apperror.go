@@ -0,0 +1,176 @@
+// Package apperror defines the typed errors returned across the service. Every
+// error carries a catalog code that decides its HTTP status and whether the
+// failed operation is worth retrying.
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Error codes, mirrored by mock-data/error_responses.json
+const (
+	CodeValidation         = "VALIDATION_ERROR"
+	CodeInvalidRequest     = "INVALID_REQUEST"
+	CodeInvalidPromoCode   = "INVALID_PROMO_CODE"
+	CodeMissingTracerID    = "MISSING_TRACER_ID"
+	CodeMissingAPIKey      = "MISSING_API_KEY"
+	CodeInvalidAPIKey      = "INVALID_API_KEY"
+	CodeUnauthorized       = "UNAUTHORIZED"
+	CodeEndpointNotAllowed = "ENDPOINT_NOT_ALLOWED"
+	CodeNotFound           = "NOT_FOUND"
+	CodeRateLimitExceeded  = "RATE_LIMIT_EXCEEDED"
+	CodeQuotaExceeded      = "QUOTA_EXCEEDED"
+	CodeService            = "SERVICE_ERROR"
+	CodeProvider           = "PROVIDER_ERROR"
+	CodeProviderThrottled  = "PROVIDER_THROTTLED"
+	CodeProviderTimeout    = "PROVIDER_TIMEOUT"
+	CodeInternal           = "INTERNAL_ERROR"
+)
+
+// Definition is the catalog entry of an error code
+type Definition struct {
+	Status    int
+	Retryable bool
+	Message   string
+}
+
+// Catalog holds every error code the service returns. Unknown codes are
+// reported as INTERNAL_ERROR.
+var Catalog = map[string]Definition{
+	CodeValidation:         {http.StatusBadRequest, false, "Origin, destination, and departure date are required"},
+	CodeInvalidRequest:     {http.StatusBadRequest, false, "Invalid JSON format or missing required fields"},
+	CodeInvalidPromoCode:   {http.StatusBadRequest, false, "Promo code cannot be applied"},
+	CodeMissingTracerID:    {http.StatusBadRequest, false, "X-Tracer-ID header is required"},
+	CodeMissingAPIKey:      {http.StatusUnauthorized, false, "X-API-Key header is required"},
+	CodeInvalidAPIKey:      {http.StatusUnauthorized, false, "API key is invalid or has been revoked"},
+	CodeUnauthorized:       {http.StatusUnauthorized, false, "A valid X-Admin-Key header is required"},
+	CodeEndpointNotAllowed: {http.StatusForbidden, false, "Your plan does not include this endpoint"},
+	CodeNotFound:           {http.StatusNotFound, false, "Resource not found"},
+	CodeRateLimitExceeded:  {http.StatusTooManyRequests, true, "Too many requests, please try again later"},
+	CodeQuotaExceeded:      {http.StatusTooManyRequests, false, "Search quota is used up"},
+	CodeService:            {http.StatusServiceUnavailable, true, "All flight providers are currently unavailable"},
+	CodeProvider:           {http.StatusBadGateway, true, "One or more flight providers are temporarily unavailable"},
+	// Retrying into a full bulkhead only adds load, the next search gets a fresh chance
+	CodeProviderThrottled: {http.StatusServiceUnavailable, false, "Too many concurrent requests to the provider"},
+	CodeProviderTimeout:   {http.StatusGatewayTimeout, true, "Flight provider did not respond in time"},
+	CodeInternal:          {http.StatusInternalServerError, false, "An unexpected error occurred while processing your request"},
+}
+
+// Error is an error with a catalog code. Its message is safe to show to clients,
+// the cause is kept for logs and errors.Is/As.
+type Error struct {
+	Code      string
+	Message   string
+	Status    int
+	Retryable bool
+	Cause     error
+	// ResetAt tells the client when a quota allows requests again
+	ResetAt *time.Time
+	// Fields lists every invalid field of a VALIDATION_ERROR
+	Fields []FieldError
+}
+
+// FieldError describes one invalid request field
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// New returns an error for code. An empty message uses the catalog message.
+func New(code, message string) *Error {
+	def, ok := Catalog[code]
+	if !ok {
+		def = Catalog[CodeInternal]
+	}
+	if message == "" {
+		message = def.Message
+	}
+	return &Error{
+		Code:      code,
+		Message:   message,
+		Status:    def.Status,
+		Retryable: def.Retryable,
+	}
+}
+
+// Newf returns an error for code with a formatted message
+func Newf(code, format string, args ...interface{}) *Error {
+	return New(code, fmt.Sprintf(format, args...))
+}
+
+// Wrap returns an error for code caused by cause
+func Wrap(code string, cause error, message string) *Error {
+	e := New(code, message)
+	e.Cause = cause
+	return e
+}
+
+// Wrapf returns an error for code caused by cause with a formatted message
+func Wrapf(code string, cause error, format string, args ...interface{}) *Error {
+	return Wrap(code, cause, fmt.Sprintf(format, args...))
+}
+
+// Validation returns a VALIDATION_ERROR listing fields. Its message joins the
+// field messages for clients that only read the message.
+func Validation(fields []FieldError) *Error {
+	messages := make([]string, len(fields))
+	for i, f := range fields {
+		messages[i] = f.Field + " " + f.Message
+	}
+	e := New(CodeValidation, strings.Join(messages, "; "))
+	e.Fields = fields
+	return e
+}
+
+// Error keeps the "CODE: message" form so logs read the same as before
+func (e *Error) Error() string {
+	if e.Cause != nil {
+		return e.Code + ": " + e.Message + ": " + e.Cause.Error()
+	}
+	return e.Code + ": " + e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// Is matches any *Error with the same code, so the sentinels below work with errors.Is
+func (e *Error) Is(target error) bool {
+	var t *Error
+	if !errors.As(target, &t) {
+		return false
+	}
+	return t.Code == e.Code
+}
+
+// Sentinels for errors.Is checks by code
+var (
+	ErrValidation        = New(CodeValidation, "")
+	ErrInvalidPromoCode  = New(CodeInvalidPromoCode, "")
+	ErrNotFound          = New(CodeNotFound, "")
+	ErrService           = New(CodeService, "")
+	ErrProvider          = New(CodeProvider, "")
+	ErrProviderThrottled = New(CodeProviderThrottled, "")
+	ErrProviderTimeout   = New(CodeProviderTimeout, "")
+	ErrInternal          = New(CodeInternal, "")
+)
+
+// From returns the *Error in err's chain, or INTERNAL_ERROR wrapping err when
+// there is none. Internal details of unknown errors never reach the message.
+func From(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return Wrap(CodeInternal, err, "")
+}
+
+// CodeOf returns the code of err, INTERNAL_ERROR for untyped errors
+func CodeOf(err error) string {
+	return From(err).Code
+}
@@ -0,0 +1,93 @@
+package apperror
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+)
+
+func TestCatalogMatchesErrorResponses(t *testing.T) {
+	data, err := os.ReadFile("../../mock-data/error_responses.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var responses map[string]struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(data, &responses); err != nil {
+		t.Fatal(err)
+	}
+
+	seen := make(map[string]bool)
+	for name, resp := range responses {
+		def, ok := Catalog[resp.Code]
+		if !ok {
+			t.Errorf("%s: code %s is not in the catalog", name, resp.Code)
+			continue
+		}
+		if def.Message != resp.Message {
+			t.Errorf("%s: expected message %q, got %q", resp.Code, resp.Message, def.Message)
+		}
+		seen[resp.Code] = true
+	}
+	for code := range Catalog {
+		if !seen[code] {
+			t.Errorf("Catalog code %s is missing from error_responses.json", code)
+		}
+	}
+}
+
+func TestError(t *testing.T) {
+	cause := context.DeadlineExceeded
+	err := fmt.Errorf("search failed: %w", Wrap(CodeProviderTimeout, cause, "Garuda did not respond"))
+
+	if !errors.Is(err, ErrProviderTimeout) {
+		t.Error("Expected errors.Is to match by code")
+	}
+	if errors.Is(err, ErrProvider) {
+		t.Error("Expected a different code not to match")
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("Expected errors.Is to reach the cause")
+	}
+
+	var appErr *Error
+	if !errors.As(err, &appErr) {
+		t.Fatal("Expected errors.As to find the *Error")
+	}
+	if appErr.Status != http.StatusGatewayTimeout || !appErr.Retryable {
+		t.Errorf("Expected catalog status and retryability, got %+v", appErr)
+	}
+	if appErr.Message != "Garuda did not respond" {
+		t.Errorf("Unexpected message %q", appErr.Message)
+	}
+}
+
+func TestFrom(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		expectedCode string
+		expectedMsg  string
+	}{
+		{"typed", Newf(CodeNotFound, "Tenant %s not found", "acme"), CodeNotFound, "Tenant acme not found"},
+		{"default message", New(CodeService, ""), CodeService, Catalog[CodeService].Message},
+		{"untyped", errors.New("redis: connection refused"), CodeInternal, Catalog[CodeInternal].Message},
+		{"unknown code", New("SOMETHING_ELSE", ""), "SOMETHING_ELSE", Catalog[CodeInternal].Message},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := From(tt.err)
+			if got.Code != tt.expectedCode || got.Message != tt.expectedMsg {
+				t.Errorf("Expected %s %q, got %s %q", tt.expectedCode, tt.expectedMsg, got.Code, got.Message)
+			}
+		})
+	}
+}
@@ -19,6 +19,29 @@ const (
 	DefaultLogDir                = "logs"
 	DefaultMaxRetries            = 3
 	DefaultRetryDelay            = 100 * time.Millisecond
+	DefaultRetryMaxDelay         = 2 * time.Second
+	DefaultRetryBudgetRatio      = 0.2
+	DefaultRetryBudgetMinRetries = 10
+	DefaultHedgeEnabled          = false
+	DefaultHedgePercentile       = 95.0
+	DefaultHedgeMaxRatio         = 0.1
+	DefaultBulkheadMaxConcurrent = 50
+	DefaultBulkheadQueueTimeout  = 50 * time.Millisecond
+	DefaultAPIKeyRequired        = false
+	DefaultRateLimitFailureMode  = RateLimitFailureLocal
+	DefaultRateLimitInstances    = 1
+	DefaultRateLimitRedisRecheck = 5 * time.Second
+	DefaultPricingReloadInterval = 30 * time.Second
+	DefaultSearchTTL             = 15 * time.Minute
+	DefaultDiversifyTopN         = 20
+	DefaultDiversifyAirlineCap   = 3
+)
+
+// Rate limiter behaviour while Redis is unavailable
+const (
+	RateLimitFailureOpen   = "open"
+	RateLimitFailureClosed = "closed"
+	RateLimitFailureLocal  = "local"
 )
 
 type Config struct {
@@ -31,6 +54,28 @@ type Config struct {
 	LogDir                string
 	MaxRetries            int
 	RetryDelay            time.Duration
+	RetryMaxDelay         time.Duration
+	RetryBudgetRatio      float64
+	RetryBudgetMinRetries int
+	HedgeEnabled          bool
+	HedgePercentile       float64
+	HedgeMaxRatio         float64
+	BulkheadMaxConcurrent int
+	BulkheadQueueTimeout  time.Duration
+	APIKeyRequired        bool
+	AdminAPIKey           string
+	PlansFile             string
+	RateLimitFailureMode  string
+	RateLimitInstances    int
+	RateLimitRedisRecheck time.Duration
+	RateLimitCostsFile    string
+	AnonSearchesPerDay    int
+	AnonSearchesPerMonth  int
+	PricingRulesFile      string
+	PricingReloadInterval time.Duration
+	SearchTTL             time.Duration
+	DiversifyTopN         int
+	DiversifyAirlineCap   int
 }
 
 // Load creates and validates configuration from environment variables
@@ -45,6 +90,28 @@ func Load() (*Config, error) {
 		LogDir:                getEnvString("LOG_DIR", DefaultLogDir),
 		MaxRetries:            getEnvInt("MAX_RETRIES", DefaultMaxRetries),
 		RetryDelay:            getEnvDuration("RETRY_DELAY", DefaultRetryDelay),
+		RetryMaxDelay:         getEnvDuration("RETRY_MAX_DELAY", DefaultRetryMaxDelay),
+		RetryBudgetRatio:      getEnvFloat("RETRY_BUDGET_RATIO", DefaultRetryBudgetRatio),
+		RetryBudgetMinRetries: getEnvInt("RETRY_BUDGET_MIN_RETRIES", DefaultRetryBudgetMinRetries),
+		HedgeEnabled:          getEnvBool("HEDGE_ENABLED", DefaultHedgeEnabled),
+		HedgePercentile:       getEnvFloat("HEDGE_PERCENTILE", DefaultHedgePercentile),
+		HedgeMaxRatio:         getEnvFloat("HEDGE_MAX_RATIO", DefaultHedgeMaxRatio),
+		BulkheadMaxConcurrent: getEnvInt("BULKHEAD_MAX_CONCURRENT", DefaultBulkheadMaxConcurrent),
+		BulkheadQueueTimeout:  getEnvDuration("BULKHEAD_QUEUE_TIMEOUT", DefaultBulkheadQueueTimeout),
+		APIKeyRequired:        getEnvBool("API_KEY_REQUIRED", DefaultAPIKeyRequired),
+		AdminAPIKey:           getEnvString("ADMIN_API_KEY", ""),
+		PlansFile:             getEnvString("PLANS_FILE", ""),
+		RateLimitFailureMode:  getEnvString("RATE_LIMIT_FAILURE_MODE", DefaultRateLimitFailureMode),
+		RateLimitInstances:    getEnvInt("RATE_LIMIT_INSTANCES", DefaultRateLimitInstances),
+		RateLimitRedisRecheck: getEnvDuration("RATE_LIMIT_REDIS_RECHECK", DefaultRateLimitRedisRecheck),
+		RateLimitCostsFile:    getEnvString("RATE_LIMIT_COSTS_FILE", ""),
+		AnonSearchesPerDay:    getEnvInt("ANON_SEARCHES_PER_DAY", 0),
+		AnonSearchesPerMonth:  getEnvInt("ANON_SEARCHES_PER_MONTH", 0),
+		PricingRulesFile:      getEnvString("PRICING_RULES_FILE", ""),
+		PricingReloadInterval: getEnvDuration("PRICING_RELOAD_INTERVAL", DefaultPricingReloadInterval),
+		SearchTTL:             getEnvDuration("SEARCH_TTL", DefaultSearchTTL),
+		DiversifyTopN:         getEnvInt("DIVERSIFY_TOP_N", DefaultDiversifyTopN),
+		DiversifyAirlineCap:   getEnvInt("DIVERSIFY_AIRLINE_CAP", DefaultDiversifyAirlineCap),
 	}
 
 	if err := cfg.validate(); err != nil {
@@ -71,6 +138,38 @@ func (c *Config) validate() error {
 	if c.MaxRetries < 0 {
 		return fmt.Errorf("MAX_RETRIES cannot be negative")
 	}
+	if c.RetryBudgetRatio < 0 {
+		return fmt.Errorf("RETRY_BUDGET_RATIO cannot be negative")
+	}
+	if c.HedgePercentile <= 0 || c.HedgePercentile > 100 {
+		return fmt.Errorf("HEDGE_PERCENTILE must be between 0 and 100")
+	}
+	if c.HedgeMaxRatio < 0 || c.HedgeMaxRatio > 1 {
+		return fmt.Errorf("HEDGE_MAX_RATIO must be between 0 and 1")
+	}
+	if c.BulkheadMaxConcurrent <= 0 {
+		return fmt.Errorf("BULKHEAD_MAX_CONCURRENT must be positive")
+	}
+	switch c.RateLimitFailureMode {
+	case RateLimitFailureOpen, RateLimitFailureClosed, RateLimitFailureLocal:
+	default:
+		return fmt.Errorf("RATE_LIMIT_FAILURE_MODE must be one of open, closed, local")
+	}
+	if c.RateLimitInstances <= 0 {
+		return fmt.Errorf("RATE_LIMIT_INSTANCES must be positive")
+	}
+	if c.PricingReloadInterval < 0 {
+		return fmt.Errorf("PRICING_RELOAD_INTERVAL cannot be negative")
+	}
+	if c.SearchTTL <= 0 {
+		return fmt.Errorf("SEARCH_TTL must be positive")
+	}
+	if c.DiversifyTopN <= 0 {
+		return fmt.Errorf("DIVERSIFY_TOP_N must be positive")
+	}
+	if c.DiversifyAirlineCap <= 0 {
+		return fmt.Errorf("DIVERSIFY_AIRLINE_CAP must be positive")
+	}
 	return nil
 }
 
@@ -101,6 +200,16 @@ func getEnvFloat(key string, defaultValue float64) float64 {
 	return defaultValue
 }
 
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+		log.Printf("Invalid boolean value for %s: %s, using default: %t", key, value, defaultValue)
+	}
+	return defaultValue
+}
+
 func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
 		if duration, err := time.ParseDuration(value); err == nil {
@@ -117,4 +226,4 @@ func MustLoad() *Config {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 	return cfg
-}
\ No newline at end of file
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -44,4 +45,52 @@ func TestGetEnvDuration(t *testing.T) {
 	if result != expected {
 		t.Errorf("Expected %v, got %v", expected, result)
 	}
-}
\ No newline at end of file
+}
+
+func TestForProvider(t *testing.T) {
+	os.Setenv("PROVIDER_LION_AIR_MAX_RETRIES", "1")
+	defer os.Unsetenv("PROVIDER_LION_AIR_MAX_RETRIES")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if settings := cfg.ForProvider("Lion Air"); settings.MaxRetries != 1 {
+		t.Errorf("Expected overridden max retries 1, got %d", settings.MaxRetries)
+	}
+	if settings := cfg.ForProvider("Garuda Indonesia"); settings.MaxRetries != cfg.MaxRetries {
+		t.Errorf("Expected default max retries %d, got %d", cfg.MaxRetries, settings.MaxRetries)
+	}
+}
+
+func TestLoadPricingRules(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		expectError bool
+	}{
+		{"valid rules", `[{"id": "fee", "kind": "fee", "mode": "fixed", "value": 25000}, {"id": "promo", "kind": "discount", "mode": "percentage", "value": 10, "match": {"routes": ["CGK-DPS"]}}]`, false},
+		{"unknown kind", `[{"id": "x", "kind": "surcharge", "mode": "fixed", "value": 1}]`, true},
+		{"negative value", `[{"id": "x", "kind": "markup", "mode": "fixed", "value": -1}]`, true},
+		{"duplicate id", `[{"id": "x", "kind": "fee", "mode": "fixed", "value": 1}, {"id": "x", "kind": "fee", "mode": "fixed", "value": 2}]`, true},
+		{"empty departure window", `[{"id": "x", "kind": "fee", "mode": "fixed", "value": 1, "match": {"departureAfter": "2025-12-01T00:00:00Z", "departureBefore": "2025-11-01T00:00:00Z"}}]`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "pricing.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := LoadPricingRules(path)
+			if tt.expectError && err == nil {
+				t.Error("Expected error but got none")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// RateLimitCosts is the single place where the rate-limit cost of a request is configured.
+// A request costs its route cost plus surcharges for its shape.
+type RateLimitCosts struct {
+	Default  int            `json:"default"`  // routes not listed in Routes
+	Routes   map[string]int `json:"routes"`   // "METHOD /path" -> base cost
+	ExtraLeg int            `json:"extraLeg"` // per leg beyond the first, e.g. a return flight
+}
+
+// DefaultRateLimitCosts is used when RATE_LIMIT_COSTS_FILE is not set
+var DefaultRateLimitCosts = RateLimitCosts{
+	Default: 1,
+	Routes: map[string]int{
+		"POST /api/flights/search": 1,
+		"GET /api/flights/filters": 0,
+		"GET /api/searches/:id":    1,
+		"GET /api/usage":           0,
+	},
+	ExtraLeg: 1,
+}
+
+// LoadRateLimitCosts reads the cost table from a JSON file. An empty path returns the defaults.
+func LoadRateLimitCosts(path string) (RateLimitCosts, error) {
+	if path == "" {
+		return DefaultRateLimitCosts, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return RateLimitCosts{}, fmt.Errorf("failed to read rate limit costs file: %w", err)
+	}
+
+	// Values missing from the file keep their defaults
+	costs := DefaultRateLimitCosts
+	costs.Routes = make(map[string]int, len(DefaultRateLimitCosts.Routes))
+	for route, cost := range DefaultRateLimitCosts.Routes {
+		costs.Routes[route] = cost
+	}
+	if err := json.Unmarshal(data, &costs); err != nil {
+		return RateLimitCosts{}, fmt.Errorf("failed to parse rate limit costs file: %w", err)
+	}
+	return costs, nil
+}
+
+// PageCost returns the cost of reading a page of a stored search, which costs the
+// same whether the cursor is sent to the search route or to the stored search
+func (rc RateLimitCosts) PageCost() int {
+	return rc.RouteCost(http.MethodGet, "/api/searches/:id")
+}
+
+// RouteCost returns the base cost of a route
+func (rc RateLimitCosts) RouteCost(method, path string) int {
+	if cost, ok := rc.Routes[method+" "+path]; ok {
+		return cost
+	}
+	return rc.Default
+}
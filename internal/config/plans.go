@@ -0,0 +1,65 @@
+package config
+
+import (
+	"encoding/json"
+	"flight-aggregator/internal/models"
+	"fmt"
+	"os"
+)
+
+// DefaultPlans is the built-in plan catalog, used when PLANS_FILE is not set
+var DefaultPlans = map[string]models.Plan{
+	"free": {
+		Name:              "free",
+		RequestsPerSecond: 1,
+		RequestsPerDay:    1000,
+		Burst:             5,
+		AllowedEndpoints:  []string{"/api/flights/search", "/api/flights/filters", "/api/searches/:id", "/api/usage"},
+		SearchesPerDay:    200,
+		SearchesPerMonth:  3000,
+	},
+	"partner": {
+		Name:              "partner",
+		RequestsPerSecond: 10,
+		RequestsPerDay:    100000,
+		Burst:             30,
+		SearchesPerDay:    20000,
+		SearchesPerMonth:  500000,
+	},
+	"enterprise": {
+		Name:              "enterprise",
+		RequestsPerSecond: 50,
+		RequestsPerDay:    1000000,
+		Burst:             100,
+	},
+}
+
+// LoadPlans reads the plan catalog from a JSON file containing an array of plans.
+// An empty path returns the built-in catalog.
+func LoadPlans(path string) (map[string]models.Plan, error) {
+	if path == "" {
+		return DefaultPlans, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plans file: %w", err)
+	}
+
+	var plans []models.Plan
+	if err := json.Unmarshal(data, &plans); err != nil {
+		return nil, fmt.Errorf("failed to parse plans file: %w", err)
+	}
+
+	catalog := make(map[string]models.Plan, len(plans))
+	for _, plan := range plans {
+		if plan.Name == "" || plan.RequestsPerSecond <= 0 {
+			return nil, fmt.Errorf("plan %q must have a name and a positive requestsPerSecond", plan.Name)
+		}
+		if plan.Burst < 1 {
+			plan.Burst = 1
+		}
+		catalog[plan.Name] = plan
+	}
+	return catalog, nil
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"encoding/json"
+	"flight-aggregator/internal/models"
+	"fmt"
+	"os"
+)
+
+// LoadPricingRules reads pricing rules from a JSON file containing an array of rules.
+// An empty path means no rules, so selling prices equal the provider fares.
+func LoadPricingRules(path string) ([]models.PricingRule, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pricing rules file: %w", err)
+	}
+
+	var rules []models.PricingRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse pricing rules file: %w", err)
+	}
+
+	seen := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		if err := validatePricingRule(rule); err != nil {
+			return nil, err
+		}
+		if seen[rule.ID] {
+			return nil, fmt.Errorf("duplicate pricing rule id %q", rule.ID)
+		}
+		seen[rule.ID] = true
+	}
+	return rules, nil
+}
+
+func validatePricingRule(rule models.PricingRule) error {
+	if rule.ID == "" {
+		return fmt.Errorf("pricing rule must have an id")
+	}
+	switch rule.Kind {
+	case models.PricingKindMarkup, models.PricingKindDiscount, models.PricingKindFee:
+	default:
+		return fmt.Errorf("pricing rule %q has unknown kind %q", rule.ID, rule.Kind)
+	}
+	switch rule.Mode {
+	case models.PricingModeFixed, models.PricingModePercentage:
+	default:
+		return fmt.Errorf("pricing rule %q has unknown mode %q", rule.ID, rule.Mode)
+	}
+	if rule.Value < 0 {
+		return fmt.Errorf("pricing rule %q value cannot be negative, use kind discount instead", rule.ID)
+	}
+	if rule.Mode == models.PricingModePercentage && rule.Kind == models.PricingKindDiscount && rule.Value > 100 {
+		return fmt.Errorf("pricing rule %q cannot discount more than 100%%", rule.ID)
+	}
+	match := rule.Match
+	if match.DepartureAfter != nil && match.DepartureBefore != nil && !match.DepartureAfter.Before(*match.DepartureBefore) {
+		return fmt.Errorf("pricing rule %q departure window is empty", rule.ID)
+	}
+	return nil
+}
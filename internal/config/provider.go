@@ -0,0 +1,49 @@
+package config
+
+import (
+	"strings"
+	"time"
+)
+
+// ProviderSettings holds per-provider overrides of the global configuration
+type ProviderSettings struct {
+	MaxRetries    int
+	RetryDelay    time.Duration
+	RetryMaxDelay time.Duration
+
+	HedgeEnabled    bool
+	HedgePercentile float64
+	HedgeMaxRatio   float64
+
+	BulkheadMaxConcurrent int
+	BulkheadQueueTimeout  time.Duration
+}
+
+// ForProvider returns the settings for a provider. Each value can be overridden with
+// an environment variable prefixed by the provider name, e.g. PROVIDER_LION_AIR_MAX_RETRIES.
+func (c *Config) ForProvider(name string) ProviderSettings {
+	prefix := providerEnvPrefix(name)
+	return ProviderSettings{
+		MaxRetries:    getEnvInt(prefix+"MAX_RETRIES", c.MaxRetries),
+		RetryDelay:    getEnvDuration(prefix+"RETRY_DELAY", c.RetryDelay),
+		RetryMaxDelay: getEnvDuration(prefix+"RETRY_MAX_DELAY", c.RetryMaxDelay),
+
+		HedgeEnabled:    getEnvBool(prefix+"HEDGE_ENABLED", c.HedgeEnabled),
+		HedgePercentile: getEnvFloat(prefix+"HEDGE_PERCENTILE", c.HedgePercentile),
+		HedgeMaxRatio:   getEnvFloat(prefix+"HEDGE_MAX_RATIO", c.HedgeMaxRatio),
+
+		BulkheadMaxConcurrent: getEnvInt(prefix+"BULKHEAD_MAX_CONCURRENT", c.BulkheadMaxConcurrent),
+		BulkheadQueueTimeout:  getEnvDuration(prefix+"BULKHEAD_QUEUE_TIMEOUT", c.BulkheadQueueTimeout),
+	}
+}
+
+// providerEnvPrefix converts "Garuda Indonesia" into "PROVIDER_GARUDA_INDONESIA_"
+func providerEnvPrefix(name string) string {
+	key := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, strings.ToUpper(strings.TrimSpace(name)))
+	return "PROVIDER_" + key + "_"
+}
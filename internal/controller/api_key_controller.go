@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"flight-aggregator/internal/apperror"
+	"flight-aggregator/internal/models"
+	"flight-aggregator/internal/usecase"
+	"flight-aggregator/internal/utils"
+	"net/http"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+type APIKeyController struct {
+	apiKeyUsecase usecase.APIKeyUsecase
+	logger        *utils.Logger
+	validate      *validator.Validate
+}
+
+func NewAPIKeyController(apiKeyUsecase usecase.APIKeyUsecase) *APIKeyController {
+	return &APIKeyController{
+		apiKeyUsecase: apiKeyUsecase,
+		logger:        utils.NewLogger(),
+		validate:      validator.New(),
+	}
+}
+
+func (kc *APIKeyController) CreateKey(c echo.Context) error {
+	startTime := time.Now()
+
+	var req models.CreateAPIKeyRequest
+	if err := c.Bind(&req); err != nil {
+		kc.logger.LogRequest(c, nil)
+		return apperror.Wrap(apperror.CodeInvalidRequest, err, "Invalid JSON format")
+	}
+
+	kc.logger.LogRequest(c, req)
+
+	if err := kc.validate.Struct(req); err != nil {
+		return apperror.New(apperror.CodeValidation, "Missing required fields: "+err.Error())
+	}
+
+	response, err := kc.apiKeyUsecase.CreateKey(c.Request().Context(), req)
+	if err != nil {
+		return err
+	}
+
+	// The raw key is only shown once, keep it out of the logs
+	kc.logger.LogResponse(c, http.StatusCreated, response.APIKey, startTime)
+	return c.JSON(http.StatusCreated, response)
+}
+
+func (kc *APIKeyController) ListKeys(c echo.Context) error {
+	startTime := time.Now()
+	kc.logger.LogRequest(c, nil)
+
+	apiKeys, err := kc.apiKeyUsecase.ListKeys(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	kc.logger.LogResponse(c, http.StatusOK, apiKeys, startTime)
+	return c.JSON(http.StatusOK, apiKeys)
+}
+
+func (kc *APIKeyController) RevokeKey(c echo.Context) error {
+	startTime := time.Now()
+	kc.logger.LogRequest(c, nil)
+
+	if err := kc.apiKeyUsecase.RevokeKey(c.Request().Context(), c.Param("id")); err != nil {
+		return err
+	}
+
+	kc.logger.LogResponse(c, http.StatusNoContent, nil, startTime)
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (kc *APIKeyController) ListPlans(c echo.Context) error {
+	startTime := time.Now()
+	kc.logger.LogRequest(c, nil)
+
+	plans, err := kc.apiKeyUsecase.ListPlans(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	kc.logger.LogResponse(c, http.StatusOK, plans, startTime)
+	return c.JSON(http.StatusOK, plans)
+}
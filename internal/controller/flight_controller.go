@@ -1,11 +1,12 @@
 package controller
 
 import (
+	"flight-aggregator/internal/apperror"
+	"flight-aggregator/internal/middleware"
 	"flight-aggregator/internal/models"
 	"flight-aggregator/internal/usecase"
 	"flight-aggregator/internal/utils"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/labstack/echo/v4"
@@ -28,13 +29,7 @@ func (fc *FlightController) SearchFlights(c echo.Context) error {
 	
 	// Check Config and usecase available
 	if fc == nil || fc.flightUsecase == nil {
-		errorResp := models.ErrorResponse{
-			Status:  "error",
-			Code:    "INTERNAL_ERROR",
-			Message: "Service not available",
-		}
-		fc.logger.LogResponse(c, http.StatusInternalServerError, errorResp, startTime)
-		return c.JSON(http.StatusInternalServerError, errorResp)
+		return apperror.New(apperror.CodeInternal, "Service not available")
 	}
 
 	// Combined request structure
@@ -45,62 +40,62 @@ func (fc *FlightController) SearchFlights(c echo.Context) error {
 	
 	var combined CombinedRequest
 	if err := c.Bind(&combined); err != nil {
-		errorResp := models.ErrorResponse{
-			Status:  "error",
-			Code:    "INVALID_REQUEST",
-			Message: "Invalid JSON format",
-		}
 		fc.logger.LogRequest(c, nil)
-		fc.logger.LogResponse(c, http.StatusBadRequest, errorResp, startTime)
-		return c.JSON(http.StatusBadRequest, errorResp)
+		return apperror.Wrap(apperror.CodeInvalidRequest, err, "Invalid JSON format")
 	}
 	
 	req := combined.SearchRequest
 	filters := combined.FilterOptions
+	filters.Debug = middleware.DebugFromContext(c)
 	
 	fc.logger.LogRequest(c, combined)
 
-	// Validate required fields
-	if err := req.Validate(); err != nil {
-		errorResp := models.ErrorResponse{
-			Status:  "error",
-			Code:    "VALIDATION_ERROR",
-			Message: "Missing required fields: " + err.Error(),
-		}
-		fc.logger.LogResponse(c, http.StatusBadRequest, errorResp, startTime)
-		return c.JSON(http.StatusBadRequest, errorResp)
+	// Validate the request and filters together so every invalid field is reported. A
+	// cursor pages through an earlier search, so the search fields are not needed.
+	fieldErrors := filters.FieldErrors()
+	if filters.Cursor == "" {
+		fieldErrors = append(req.FieldErrors(startTime), fieldErrors...)
+	}
+	if len(fieldErrors) > 0 {
+		return apperror.Validation(fieldErrors)
 	}
 
 	// Business Process to search - use expected format
-	response, err := fc.flightUsecase.SearchFlightsExpected(c.Request().Context(), req, filters)
+	response, err := fc.flightUsecase.SearchFlightsExpected(c.Request().Context(), req, filters, middleware.TenantFromContext(c))
+	if err != nil {
+		return err
+	}
+
+	fc.logger.LogResponse(c, http.StatusOK, response, startTime)
+	return c.JSON(http.StatusOK, response)
+}
+
+// GetSearch presents a stored search again. Filters, sorting and paging come from
+// the query string, the providers are not queried.
+func (fc *FlightController) GetSearch(c echo.Context) error {
+	startTime := time.Now()
+
+	if fc == nil || fc.flightUsecase == nil {
+		return apperror.New(apperror.CodeInternal, "Service not available")
+	}
+
+	// Only the query string, the default binder would also bind the :id path param
+	var filters models.FilterOptions
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, &filters); err != nil {
+		fc.logger.LogRequest(c, nil)
+		return apperror.Wrap(apperror.CodeInvalidRequest, err, "Invalid query parameters")
+	}
+	filters.Debug = middleware.DebugFromContext(c)
+
+	fc.logger.LogRequest(c, filters)
+
+	if fieldErrors := filters.FieldErrors(); len(fieldErrors) > 0 {
+		return apperror.Validation(fieldErrors)
+	}
+
+	response, err := fc.flightUsecase.GetSearch(c.Request().Context(), c.Param("id"), filters, middleware.TenantFromContext(c))
 	if err != nil {
-		errorMsg := err.Error()
-		var statusCode int
-		var errorCode string
-
-		if contains(errorMsg, "VALIDATION_ERROR") {
-			statusCode = http.StatusBadRequest
-			errorCode = "VALIDATION_ERROR"
-		} else if contains(errorMsg, "SERVICE_ERROR") {
-			statusCode = http.StatusServiceUnavailable
-			errorCode = "SERVICE_ERROR"
-		} else {
-			statusCode = http.StatusInternalServerError
-			errorCode = "INTERNAL_ERROR"
-		}
-
-		message := errorMsg
-		if idx := strings.Index(errorMsg, ": "); idx != -1 {
-			message = errorMsg[idx+2:]
-		}
-
-		errorResp := models.ErrorResponse{
-			Status:  "error",
-			Code:    errorCode,
-			Message: message,
-		}
-		fc.logger.LogResponse(c, statusCode, errorResp, startTime)
-		return c.JSON(statusCode, errorResp)
+		return err
 	}
 
 	fc.logger.LogResponse(c, http.StatusOK, response, startTime)
@@ -112,27 +107,15 @@ func (fc *FlightController) GetFilters(c echo.Context) error {
 	
 	// Check Config and usecase available
 	if fc == nil || fc.flightUsecase == nil {
-		errorResp := models.ErrorResponse{
-			Status:  "error",
-			Code:    "INTERNAL_ERROR",
-			Message: "Service not available",
-		}
-		fc.logger.LogResponse(c, http.StatusInternalServerError, errorResp, startTime)
-		return c.JSON(http.StatusInternalServerError, errorResp)
+		return apperror.New(apperror.CodeInternal, "Service not available")
 	}
 	
 	fc.logger.LogRequest(c, nil)
 
-	// Business Process to filter
-	filters, err := fc.flightUsecase.GetFilters(c.Request().Context())
+	// Business Process to filter, narrowed to a stored search when one is given
+	filters, err := fc.flightUsecase.GetFilters(c.Request().Context(), c.QueryParam("searchId"), middleware.TenantFromContext(c))
 	if err != nil {
-		errorResp := models.ErrorResponse{
-			Status:  "error",
-			Code:    "INTERNAL_ERROR",
-			Message: err.Error(),
-		}
-		fc.logger.LogResponse(c, http.StatusInternalServerError, errorResp, startTime)
-		return c.JSON(http.StatusInternalServerError, errorResp)
+		return err
 	}
 	
 	fc.logger.LogResponse(c, http.StatusOK, filters, startTime)
@@ -145,7 +128,3 @@ func (fc *FlightController) HealthCheck(c echo.Context) error {
 	}
 	return c.JSON(http.StatusOK, map[string]string{"status": "healthy"})
 }
-
-func contains(s, substr string) bool {
-	return strings.Contains(s, substr)
-}
\ No newline at end of file
@@ -5,11 +5,15 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"flight-aggregator/internal/apperror"
+	"flight-aggregator/internal/middleware"
 	"flight-aggregator/internal/models"
+	"flight-aggregator/internal/utils"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/labstack/echo/v4"
 )
@@ -18,16 +22,26 @@ type mockFlightUsecase struct {
 	searchResponse *models.ExpectedSearchResponse
 	filtersResponse *models.FiltersResponse
 	err            error
+	searchID       string
+	filters        models.FilterOptions
 }
 
-func (m *mockFlightUsecase) SearchFlightsExpected(ctx context.Context, req models.SearchRequest, filters models.FilterOptions) (*models.ExpectedSearchResponse, error) {
+func (m *mockFlightUsecase) SearchFlightsExpected(ctx context.Context, req models.SearchRequest, filters models.FilterOptions, tenant *models.Tenant) (*models.ExpectedSearchResponse, error) {
 	if m.err != nil {
 		return nil, m.err
 	}
 	return m.searchResponse, nil
 }
 
-func (m *mockFlightUsecase) GetFilters(ctx context.Context) (*models.FiltersResponse, error) {
+func (m *mockFlightUsecase) GetSearch(ctx context.Context, id string, filters models.FilterOptions, tenant *models.Tenant) (*models.ExpectedSearchResponse, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.searchID, m.filters = id, filters
+	return m.searchResponse, nil
+}
+
+func (m *mockFlightUsecase) GetFilters(ctx context.Context, searchID string, tenant *models.Tenant) (*models.FiltersResponse, error) {
 	if m.err != nil {
 		return nil, m.err
 	}
@@ -35,6 +49,8 @@ func (m *mockFlightUsecase) GetFilters(ctx context.Context) (*models.FiltersResp
 }
 
 func TestFlightController_SearchFlights(t *testing.T) {
+	departureDate := time.Now().AddDate(0, 1, 0).Format(models.DateFormat)
+
 	tests := []struct {
 		name           string
 		requestBody    interface{}
@@ -47,7 +63,7 @@ func TestFlightController_SearchFlights(t *testing.T) {
 			requestBody: models.SearchRequest{
 				Origin:        "CGK",
 				Destination:   "DPS",
-				DepartureDate: "2025-12-15",
+				DepartureDate: departureDate,
 				Passengers:    1,
 				CabinClass:    "economy",
 			},
@@ -80,26 +96,73 @@ func TestFlightController_SearchFlights(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			expectedError:  "VALIDATION_ERROR",
 		},
+		{
+			name: "invalid filters",
+			requestBody: map[string]interface{}{
+				"origin":        "CGK",
+				"destination":   "DPS",
+				"departureDate": departureDate,
+				"passengers":    1,
+				"cabinClass":    "economy",
+				"minPrice":      2000000,
+				"maxPrice":      1000000,
+			},
+			usecase:        &mockFlightUsecase{},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "VALIDATION_ERROR",
+		},
+		{
+			name:        "next page by cursor",
+			requestBody: map[string]interface{}{"cursor": "eyJzIjoiYWJjIiwibyI6MjAsImwiOjIwfQ"},
+			usecase: &mockFlightUsecase{
+				searchResponse: &models.ExpectedSearchResponse{Metadata: models.Metadata{TotalResults: 40, SearchID: "abc"}},
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "page size too large",
+			requestBody:    map[string]interface{}{"cursor": "eyJzIjoiYWJjIiwibyI6MjAsImwiOjIwfQ", "limit": 500},
+			usecase:        &mockFlightUsecase{},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "VALIDATION_ERROR",
+		},
 		{
 			name: "service error",
 			requestBody: models.SearchRequest{
 				Origin:        "CGK",
 				Destination:   "DPS",
-				DepartureDate: "2025-12-15",
+				DepartureDate: departureDate,
 				Passengers:    1,
 				CabinClass:    "economy",
 			},
 			usecase: &mockFlightUsecase{
-				err: errors.New("SERVICE_ERROR: All providers unavailable"),
+				err: apperror.New(apperror.CodeService, "All providers unavailable"),
 			},
 			expectedStatus: http.StatusServiceUnavailable,
 			expectedError:  "SERVICE_ERROR",
 		},
+		{
+			name: "invalid promo code",
+			requestBody: models.SearchRequest{
+				Origin:        "CGK",
+				Destination:   "DPS",
+				DepartureDate: departureDate,
+				Passengers:    1,
+				CabinClass:    "economy",
+				PromoCode:     "EXPIRED",
+			},
+			usecase: &mockFlightUsecase{
+				err: apperror.New(apperror.CodeInvalidPromoCode, "Promo code EXPIRED expired on 2025-01-01T00:00:00Z"),
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "INVALID_PROMO_CODE",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			e := echo.New()
+			e.HTTPErrorHandler = middleware.ErrorHandler(utils.NewLogger())
 			
 			var reqBody []byte
 			var err error
@@ -119,10 +182,8 @@ func TestFlightController_SearchFlights(t *testing.T) {
 			c := e.NewContext(req, rec)
 
 			controller := NewFlightController(tt.usecase)
-			err = controller.SearchFlights(c)
-
-			if err != nil {
-				t.Errorf("Unexpected error: %v", err)
+			if err = controller.SearchFlights(c); err != nil {
+				e.HTTPErrorHandler(err, c)
 			}
 
 			if rec.Code != tt.expectedStatus {
@@ -135,6 +196,12 @@ func TestFlightController_SearchFlights(t *testing.T) {
 				if errorResp.Code != tt.expectedError {
 					t.Errorf("Expected error code %s, got %s", tt.expectedError, errorResp.Code)
 				}
+				if tt.expectedError == "VALIDATION_ERROR" && len(errorResp.Errors) == 0 {
+					t.Error("Expected the invalid fields to be listed")
+				}
+				if tt.expectedError == "INVALID_REQUEST" && errorResp.Message != "Invalid JSON format" {
+					t.Errorf("Expected the bind error to stay out of the message, got %q", errorResp.Message)
+				}
 			}
 		})
 	}
@@ -168,20 +235,92 @@ func TestFlightController_GetFilters(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			e := echo.New()
+			e.HTTPErrorHandler = middleware.ErrorHandler(utils.NewLogger())
 			req := httptest.NewRequest(http.MethodGet, "/api/filters", nil)
 			rec := httptest.NewRecorder()
 			c := e.NewContext(req, rec)
 
 			controller := NewFlightController(tt.usecase)
-			err := controller.GetFilters(c)
+			if err := controller.GetFilters(c); err != nil {
+				e.HTTPErrorHandler(err, c)
+			}
 
-			if err != nil {
-				t.Errorf("Unexpected error: %v", err)
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+		})
+	}
+}
+
+func TestFlightController_GetSearch(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		usecase        *mockFlightUsecase
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:  "refilter a stored search",
+			query: "?maxStops=0&airlines=Garuda+Indonesia&airlines=Lion+Air&sortBy=price_desc&limit=10",
+			usecase: &mockFlightUsecase{
+				searchResponse: &models.ExpectedSearchResponse{Metadata: models.Metadata{SearchID: "abc"}},
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid filters",
+			query:          "?sortBy=cheapest&limit=1000",
+			usecase:        &mockFlightUsecase{},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "VALIDATION_ERROR",
+		},
+		{
+			name:           "malformed query",
+			query:          "?limit=ten",
+			usecase:        &mockFlightUsecase{},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "INVALID_REQUEST",
+		},
+		{
+			name:           "expired search",
+			usecase:        &mockFlightUsecase{err: apperror.New(apperror.CodeNotFound, "Search abc not found or expired, search again")},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "NOT_FOUND",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.HTTPErrorHandler = middleware.ErrorHandler(utils.NewLogger())
+			req := httptest.NewRequest(http.MethodGet, "/api/searches/abc"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/api/searches/:id")
+			c.SetParamNames("id")
+			c.SetParamValues("abc")
+
+			controller := NewFlightController(tt.usecase)
+			if err := controller.GetSearch(c); err != nil {
+				e.HTTPErrorHandler(err, c)
 			}
 
 			if rec.Code != tt.expectedStatus {
 				t.Errorf("Expected status %d, got %d", tt.expectedStatus, rec.Code)
 			}
+			if tt.expectedError != "" {
+				var errorResp models.ErrorResponse
+				json.Unmarshal(rec.Body.Bytes(), &errorResp)
+				if errorResp.Code != tt.expectedError {
+					t.Errorf("Expected error code %s, got %s", tt.expectedError, errorResp.Code)
+				}
+				return
+			}
+			filters := tt.usecase.filters
+			if tt.usecase.searchID != "abc" || filters.MaxStops == nil || *filters.MaxStops != 0 || len(filters.Airlines) != 2 || filters.SortBy != "price_desc" || filters.Limit != 10 {
+				t.Errorf("Expected search abc with the query filters, got %q %+v", tt.usecase.searchID, filters)
+			}
 		})
 	}
 }
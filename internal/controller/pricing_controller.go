@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"flight-aggregator/internal/apperror"
+	"flight-aggregator/internal/usecase"
+	"flight-aggregator/internal/utils"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type PricingController struct {
+	pricingEngine *usecase.PricingEngine
+	logger        *utils.Logger
+}
+
+func NewPricingController(pricingEngine *usecase.PricingEngine) *PricingController {
+	return &PricingController{
+		pricingEngine: pricingEngine,
+		logger:        utils.NewLogger(),
+	}
+}
+
+// ListRules returns the active pricing rules in evaluation order
+func (pc *PricingController) ListRules(c echo.Context) error {
+	startTime := time.Now()
+	pc.logger.LogRequest(c, nil)
+
+	rules := pc.pricingEngine.Rules()
+	pc.logger.LogResponse(c, http.StatusOK, rules, startTime)
+	return c.JSON(http.StatusOK, rules)
+}
+
+// ReloadRules reloads the pricing rules without waiting for the next scheduled reload
+func (pc *PricingController) ReloadRules(c echo.Context) error {
+	startTime := time.Now()
+	pc.logger.LogRequest(c, nil)
+
+	count, err := pc.pricingEngine.Reload()
+	if err != nil {
+		return apperror.New(apperror.CodeValidation, "Pricing rules not reloaded: "+err.Error())
+	}
+
+	response := map[string]interface{}{
+		"status": "reloaded",
+		"rules":  count,
+	}
+	pc.logger.LogResponse(c, http.StatusOK, response, startTime)
+	return c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"flight-aggregator/internal/apperror"
+	"flight-aggregator/internal/models"
+	"flight-aggregator/internal/usecase"
+	"flight-aggregator/internal/utils"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type PromoController struct {
+	promoUsecase usecase.PromoUsecase
+	logger       *utils.Logger
+}
+
+func NewPromoController(promoUsecase usecase.PromoUsecase) *PromoController {
+	return &PromoController{
+		promoUsecase: promoUsecase,
+		logger:       utils.NewLogger(),
+	}
+}
+
+// SavePromo creates or replaces the promo code named in the path
+func (pc *PromoController) SavePromo(c echo.Context) error {
+	startTime := time.Now()
+
+	var promo models.PromoCode
+	if err := c.Bind(&promo); err != nil {
+		pc.logger.LogRequest(c, nil)
+		return apperror.Wrap(apperror.CodeInvalidRequest, err, "Invalid JSON format")
+	}
+	promo.Code = c.Param("code")
+
+	pc.logger.LogRequest(c, promo)
+
+	saved, err := pc.promoUsecase.SavePromo(c.Request().Context(), promo)
+	if err != nil {
+		return err
+	}
+
+	pc.logger.LogResponse(c, http.StatusOK, saved, startTime)
+	return c.JSON(http.StatusOK, saved)
+}
+
+func (pc *PromoController) ListPromos(c echo.Context) error {
+	startTime := time.Now()
+	pc.logger.LogRequest(c, nil)
+
+	promos, err := pc.promoUsecase.ListPromos(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	pc.logger.LogResponse(c, http.StatusOK, promos, startTime)
+	return c.JSON(http.StatusOK, promos)
+}
+
+func (pc *PromoController) DeletePromo(c echo.Context) error {
+	startTime := time.Now()
+	pc.logger.LogRequest(c, nil)
+
+	if err := pc.promoUsecase.DeletePromo(c.Request().Context(), c.Param("code")); err != nil {
+		return err
+	}
+
+	pc.logger.LogResponse(c, http.StatusNoContent, nil, startTime)
+	return c.NoContent(http.StatusNoContent)
+}
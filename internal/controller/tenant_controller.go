@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"flight-aggregator/internal/apperror"
+	"flight-aggregator/internal/models"
+	"flight-aggregator/internal/usecase"
+	"flight-aggregator/internal/utils"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type TenantController struct {
+	tenantUsecase usecase.TenantUsecase
+	logger        *utils.Logger
+}
+
+func NewTenantController(tenantUsecase usecase.TenantUsecase) *TenantController {
+	return &TenantController{
+		tenantUsecase: tenantUsecase,
+		logger:        utils.NewLogger(),
+	}
+}
+
+// SaveTenant creates or replaces the tenant named in the path
+func (tc *TenantController) SaveTenant(c echo.Context) error {
+	startTime := time.Now()
+
+	var tenant models.Tenant
+	if err := c.Bind(&tenant); err != nil {
+		tc.logger.LogRequest(c, nil)
+		return apperror.Wrap(apperror.CodeInvalidRequest, err, "Invalid JSON format")
+	}
+	tenant.ID = c.Param("id")
+
+	// Credentials stay out of the logs
+	tc.logger.LogRequest(c, tenant.Redacted())
+
+	saved, err := tc.tenantUsecase.SaveTenant(c.Request().Context(), tenant)
+	if err != nil {
+		return err
+	}
+
+	tc.logger.LogResponse(c, http.StatusOK, saved, startTime)
+	return c.JSON(http.StatusOK, saved)
+}
+
+func (tc *TenantController) GetTenant(c echo.Context) error {
+	startTime := time.Now()
+	tc.logger.LogRequest(c, nil)
+
+	tenant, err := tc.tenantUsecase.GetTenant(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	tc.logger.LogResponse(c, http.StatusOK, tenant, startTime)
+	return c.JSON(http.StatusOK, tenant)
+}
+
+func (tc *TenantController) ListTenants(c echo.Context) error {
+	startTime := time.Now()
+	tc.logger.LogRequest(c, nil)
+
+	tenants, err := tc.tenantUsecase.ListTenants(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	tc.logger.LogResponse(c, http.StatusOK, tenants, startTime)
+	return c.JSON(http.StatusOK, tenants)
+}
+
+func (tc *TenantController) DeleteTenant(c echo.Context) error {
+	startTime := time.Now()
+	tc.logger.LogRequest(c, nil)
+
+	if err := tc.tenantUsecase.DeleteTenant(c.Request().Context(), c.Param("id")); err != nil {
+		return err
+	}
+
+	tc.logger.LogResponse(c, http.StatusNoContent, nil, startTime)
+	return c.NoContent(http.StatusNoContent)
+}
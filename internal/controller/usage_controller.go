@@ -0,0 +1,38 @@
+package controller
+
+import (
+	"flight-aggregator/internal/middleware"
+	"flight-aggregator/internal/usecase"
+	"flight-aggregator/internal/utils"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type UsageController struct {
+	quotaUsecase usecase.QuotaUsecase
+	logger       *utils.Logger
+}
+
+func NewUsageController(quotaUsecase usecase.QuotaUsecase) *UsageController {
+	return &UsageController{
+		quotaUsecase: quotaUsecase,
+		logger:       utils.NewLogger(),
+	}
+}
+
+// GetUsage reports the search quota consumption of the calling client
+func (uc *UsageController) GetUsage(c echo.Context) error {
+	startTime := time.Now()
+	uc.logger.LogRequest(c, nil)
+
+	clientID, plan := middleware.QuotaClient(c)
+	usage, err := uc.quotaUsecase.GetUsage(c.Request().Context(), clientID, plan)
+	if err != nil {
+		return err
+	}
+
+	uc.logger.LogResponse(c, http.StatusOK, usage, startTime)
+	return c.JSON(http.StatusOK, usage)
+}
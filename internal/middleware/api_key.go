@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"flight-aggregator/internal/apperror"
+	"flight-aggregator/internal/config"
+	"flight-aggregator/internal/models"
+	"flight-aggregator/internal/repository"
+	"log"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	APIKeyHeader   = "X-API-Key"
+	AdminKeyHeader = "X-Admin-Key"
+
+	ContextKeyAPIKey = "api_key"
+	ContextKeyPlan   = "plan"
+	ContextKeyDebug  = "debug"
+
+	DebugQueryParam = "debug"
+)
+
+// APIKeyAuth resolves the X-API-Key header to a stored key and its plan. Requests
+// without a key pass through as anonymous unless required is set. While Redis is
+// unavailable a key looked up recently is served from memory; any other key is
+// rejected in the closed failure mode and served as anonymous otherwise.
+func APIKeyAuth(repo repository.APIKeyRepository, plans map[string]models.Plan, required bool, failureMode string) echo.MiddlewareFunc {
+	keys := newLookupCache[models.APIKey]()
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			rawKey := c.Request().Header.Get(APIKeyHeader)
+			if rawKey == "" {
+				if required {
+					return apperror.New(apperror.CodeMissingAPIKey, "")
+				}
+				return next(c)
+			}
+
+			ctx, cancel := context.WithTimeout(c.Request().Context(), redisCallTimeout)
+			apiKey, err := repo.Lookup(ctx, rawKey)
+			cancel()
+
+			keyHash := repository.HashAPIKey(rawKey)
+			switch {
+			case err == nil:
+				keys.set(keyHash, *apiKey)
+			case err != repository.ErrAPIKeyNotFound:
+				cached, ok := keys.get(keyHash)
+				if ok {
+					apiKey, err = &cached, nil
+					break
+				}
+				log.Printf("Failed to look up API key: %v", err)
+				if failureMode == config.RateLimitFailureClosed {
+					return apperror.Wrap(apperror.CodeService, err, "Unable to verify API key")
+				}
+				return next(c)
+			}
+			if err == repository.ErrAPIKeyNotFound || !apiKey.Active {
+				return apperror.New(apperror.CodeInvalidAPIKey, "")
+			}
+
+			plan, ok := plans[apiKey.Plan]
+			if !ok {
+				log.Printf("API key %s references unknown plan %s", apiKey.ID, apiKey.Plan)
+				return apperror.New(apperror.CodeInvalidAPIKey, "API key has no valid plan")
+			}
+
+			if !plan.AllowsEndpoint(c.Path()) {
+				return apperror.New(apperror.CodeEndpointNotAllowed, "")
+			}
+
+			c.Set(ContextKeyAPIKey, apiKey)
+			c.Set(ContextKeyPlan, plan)
+			return next(c)
+		}
+	}
+}
+
+// AdminAuth protects management endpoints with the ADMIN_API_KEY secret.
+// An empty admin key disables the endpoints entirely.
+func AdminAuth(adminKey string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !hasAdminKey(c, adminKey) {
+				return apperror.New(apperror.CodeUnauthorized, "")
+			}
+			return next(c)
+		}
+	}
+}
+
+// DebugAccess enables debug output for requests with ?debug=true and a valid
+// X-Admin-Key. Asking for debug output without the admin key is rejected rather
+// than silently ignored.
+func DebugAccess(adminKey string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if c.QueryParam(DebugQueryParam) != "true" {
+				return next(c)
+			}
+			if !hasAdminKey(c, adminKey) {
+				return apperror.New(apperror.CodeUnauthorized, "Debug output requires a valid X-Admin-Key header")
+			}
+			c.Set(ContextKeyDebug, true)
+			return next(c)
+		}
+	}
+}
+
+// DebugFromContext reports whether DebugAccess enabled debug output
+func DebugFromContext(c echo.Context) bool {
+	debug, _ := c.Get(ContextKeyDebug).(bool)
+	return debug
+}
+
+// hasAdminKey reports whether the request carries the admin key. An empty admin
+// key matches nothing.
+func hasAdminKey(c echo.Context, adminKey string) bool {
+	provided := c.Request().Header.Get(AdminKeyHeader)
+	return adminKey != "" && subtle.ConstantTimeCompare([]byte(provided), []byte(adminKey)) == 1
+}
+
+// apiKeyFromContext returns the authenticated key and plan set by APIKeyAuth
+func apiKeyFromContext(c echo.Context) (*models.APIKey, models.Plan, bool) {
+	apiKey, ok := c.Get(ContextKeyAPIKey).(*models.APIKey)
+	if !ok {
+		return nil, models.Plan{}, false
+	}
+	plan, ok := c.Get(ContextKeyPlan).(models.Plan)
+	return apiKey, plan, ok
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"errors"
+	"flight-aggregator/internal/apperror"
+	"flight-aggregator/internal/models"
+	"flight-aggregator/internal/utils"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ContextKeyStartTime holds when the request entered the middleware chain
+const ContextKeyStartTime = "start_time"
+
+// ErrorHandler is the Echo HTTPErrorHandler. It writes every error returned by a
+// handler or middleware as a models.ErrorResponse with the status of its code.
+func ErrorHandler(logger *utils.Logger) echo.HTTPErrorHandler {
+	return func(err error, c echo.Context) {
+		if c.Response().Committed {
+			return
+		}
+
+		appErr := toAppError(err)
+		if appErr.Code == apperror.CodeInternal && appErr.Cause != nil {
+			log.Printf("Unhandled error on %s %s: %v", c.Request().Method, c.Path(), appErr.Cause)
+		}
+
+		errorResp := models.ErrorResponse{
+			Status:  "error",
+			Code:    appErr.Code,
+			Message: appErr.Message,
+			ResetAt: appErr.ResetAt,
+			Errors:  appErr.Fields,
+		}
+
+		startTime, ok := c.Get(ContextKeyStartTime).(time.Time)
+		if !ok {
+			startTime = time.Now()
+		}
+		logger.LogResponse(c, appErr.Status, errorResp, startTime)
+
+		var writeErr error
+		if c.Request().Method == http.MethodHead {
+			writeErr = c.NoContent(appErr.Status)
+		} else {
+			writeErr = c.JSON(appErr.Status, errorResp)
+		}
+		if writeErr != nil {
+			log.Printf("Failed to write error response: %v", writeErr)
+		}
+	}
+}
+
+// toAppError converts router errors such as unknown routes to catalog errors
+func toAppError(err error) *apperror.Error {
+	var appErr *apperror.Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		message := http.StatusText(httpErr.Code)
+		if msg, ok := httpErr.Message.(string); ok {
+			message = msg
+		}
+		switch {
+		case httpErr.Code == http.StatusNotFound:
+			return apperror.Wrap(apperror.CodeNotFound, err, message)
+		case httpErr.Code < http.StatusInternalServerError:
+			appErr = apperror.Wrap(apperror.CodeInvalidRequest, err, message)
+			appErr.Status = httpErr.Code
+			return appErr
+		}
+	}
+	return apperror.From(err)
+}
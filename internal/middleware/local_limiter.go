@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// localBucketIdleTTL is how long an unused bucket is kept before being swept
+const localBucketIdleTTL = 10 * time.Minute
+
+// LocalLimiter is an in-process token bucket limiter used while Redis is unavailable.
+// It only sees this instance's traffic, so callers pass a per-instance share of the limit.
+type LocalLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*localBucket
+	lastSweep time.Time
+}
+
+type localBucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+func NewLocalLimiter() *LocalLimiter {
+	return &LocalLimiter{
+		buckets:   make(map[string]*localBucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// Allow takes cost tokens from the bucket of key, refilled at rate tokens per second
+// up to capacity
+func (ll *LocalLimiter) Allow(key string, rate float64, capacity int, cost int) *RateLimitResult {
+	ll.mu.Lock()
+	defer ll.mu.Unlock()
+
+	now := time.Now()
+	ll.sweep(now)
+
+	bucket, ok := ll.buckets[key]
+	if !ok {
+		bucket = &localBucket{tokens: float64(capacity), lastSeen: now}
+		ll.buckets[key] = bucket
+	}
+
+	bucket.tokens = math.Min(float64(capacity), bucket.tokens+now.Sub(bucket.lastSeen).Seconds()*rate)
+	bucket.lastSeen = now
+
+	allowed := bucket.tokens >= float64(cost)
+	if allowed {
+		bucket.tokens -= float64(cost)
+	}
+
+	refill := time.Duration((float64(capacity) - bucket.tokens) / rate * float64(time.Second))
+	result := &RateLimitResult{
+		Allowed:   allowed,
+		Limit:     capacity,
+		Remaining: int(bucket.tokens),
+		Reset:     now.Add(refill),
+		Cost:      cost,
+	}
+	if !allowed {
+		result.RetryAfter = time.Duration((float64(cost) - bucket.tokens) / rate * float64(time.Second))
+	}
+	return result
+}
+
+// sweep drops idle buckets at most once per TTL
+func (ll *LocalLimiter) sweep(now time.Time) {
+	if now.Sub(ll.lastSweep) < localBucketIdleTTL {
+		return
+	}
+	for key, bucket := range ll.buckets {
+		if now.Sub(bucket.lastSeen) > localBucketIdleTTL {
+			delete(ll.buckets, key)
+		}
+	}
+	ll.lastSweep = now
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"sync"
+	"time"
+)
+
+// lookupCacheTTL is how long a Redis lookup can stand in for Redis once it is down
+const lookupCacheTTL = 5 * time.Minute
+
+// lookupCache keeps recent successful Redis lookups of API keys and tenants, so
+// known clients keep their plan and tenant while Redis is unavailable. It is only
+// read when Redis fails; a working Redis always has the last word.
+type lookupCache[T any] struct {
+	mu        sync.Mutex
+	entries   map[string]cachedLookup[T]
+	lastSweep time.Time
+}
+
+type cachedLookup[T any] struct {
+	value    T
+	storedAt time.Time
+}
+
+func newLookupCache[T any]() *lookupCache[T] {
+	return &lookupCache[T]{
+		entries:   make(map[string]cachedLookup[T]),
+		lastSweep: time.Now(),
+	}
+}
+
+func (lc *lookupCache[T]) set(key string, value T) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	now := time.Now()
+	lc.sweep(now)
+	lc.entries[key] = cachedLookup[T]{value: value, storedAt: now}
+}
+
+// get returns the value stored for key unless it is older than lookupCacheTTL
+func (lc *lookupCache[T]) get(key string) (T, bool) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	entry, ok := lc.entries[key]
+	if !ok || time.Since(entry.storedAt) > lookupCacheTTL {
+		var zero T
+		return zero, false
+	}
+	return entry.value, true
+}
+
+// sweep drops expired entries at most once per TTL
+func (lc *lookupCache[T]) sweep(now time.Time) {
+	if now.Sub(lc.lastSweep) < lookupCacheTTL {
+		return
+	}
+	for key, entry := range lc.entries {
+		if now.Sub(entry.storedAt) > lookupCacheTTL {
+			delete(lc.entries, key)
+		}
+	}
+	lc.lastSweep = now
+}
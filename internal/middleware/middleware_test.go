@@ -1,12 +1,692 @@
 package middleware
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flight-aggregator/internal/apperror"
+	"flight-aggregator/internal/config"
+	"flight-aggregator/internal/models"
+	"flight-aggregator/internal/repository"
+	"flight-aggregator/internal/utils"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/labstack/echo/v4"
 )
 
+// newTestEcho returns an Echo instance rendering errors like the server does
+func newTestEcho() *echo.Echo {
+	e := echo.New()
+	e.HTTPErrorHandler = ErrorHandler(utils.NewLogger())
+	return e
+}
+
 func TestTracerMiddleware(t *testing.T) {
 	middleware := TracerMiddleware()
 	if middleware == nil {
 		t.Error("TracerMiddleware should not be nil")
 	}
-}
\ No newline at end of file
+}
+
+func TestErrorHandler(t *testing.T) {
+	resetAt := time.Date(2025, 12, 16, 0, 0, 0, 0, time.UTC)
+	quotaErr := apperror.New(apperror.CodeQuotaExceeded, "")
+	quotaErr.ResetAt = &resetAt
+
+	tests := []struct {
+		name            string
+		err             error
+		expectedStatus  int
+		expectedCode    string
+		expectedMessage string
+	}{
+		{"typed error", apperror.Newf(apperror.CodeNotFound, "Tenant %s not found", "acme"), http.StatusNotFound, "NOT_FOUND", "Tenant acme not found"},
+		{"wrapped typed error", fmt.Errorf("search: %w", apperror.New(apperror.CodeService, "")), http.StatusServiceUnavailable, "SERVICE_ERROR", "All flight providers are currently unavailable"},
+		{"quota error", quotaErr, http.StatusTooManyRequests, "QUOTA_EXCEEDED", "Search quota is used up"},
+		{"unknown route", echo.ErrNotFound, http.StatusNotFound, "NOT_FOUND", "Not Found"},
+		{"method not allowed", echo.ErrMethodNotAllowed, http.StatusMethodNotAllowed, "INVALID_REQUEST", "Method Not Allowed"},
+		{"untyped error", errors.New("redis: connection refused"), http.StatusInternalServerError, "INTERNAL_ERROR", "An unexpected error occurred while processing your request"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestEcho()
+			req := httptest.NewRequest(http.MethodGet, "/api/flights/filters", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			e.HTTPErrorHandler(tt.err, c)
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+			var resp models.ErrorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Status != "error" || resp.Code != tt.expectedCode || resp.Message != tt.expectedMessage {
+				t.Errorf("Expected %s %q, got %+v", tt.expectedCode, tt.expectedMessage, resp)
+			}
+			if tt.err == quotaErr && (resp.ResetAt == nil || !resp.ResetAt.Equal(resetAt)) {
+				t.Errorf("Expected reset_at %v, got %v", resetAt, resp.ResetAt)
+			}
+		})
+	}
+}
+
+func TestSetRateLimitHeaders(t *testing.T) {
+	tests := []struct {
+		name               string
+		result             *RateLimitResult
+		expectedRemaining  string
+		expectedRetryAfter string
+	}{
+		{
+			name: "allowed request",
+			result: &RateLimitResult{
+				Allowed:   true,
+				Limit:     100,
+				Remaining: 42,
+				Reset:     time.Unix(1765000000, 0),
+				Cost:      1,
+			},
+			expectedRemaining:  "42",
+			expectedRetryAfter: "",
+		},
+		{
+			name: "rejected request",
+			result: &RateLimitResult{
+				Allowed:    false,
+				Limit:      100,
+				Remaining:  0,
+				Reset:      time.Unix(1765000000, 0),
+				RetryAfter: 1500 * time.Millisecond,
+			},
+			expectedRemaining:  "0",
+			expectedRetryAfter: "2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestEcho()
+			req := httptest.NewRequest(http.MethodGet, "/api/flights/filters", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			setRateLimitHeaders(c, tt.result)
+
+			if got := rec.Header().Get(HeaderRateLimitLimit); got != "100" {
+				t.Errorf("Expected limit 100, got %s", got)
+			}
+			if got := rec.Header().Get(HeaderRateLimitRemaining); got != tt.expectedRemaining {
+				t.Errorf("Expected remaining %s, got %s", tt.expectedRemaining, got)
+			}
+			if got := rec.Header().Get(HeaderRateLimitReset); got != "1765000000" {
+				t.Errorf("Expected reset 1765000000, got %s", got)
+			}
+			if got := rec.Header().Get(HeaderRateLimitCost); got != strconv.Itoa(tt.result.Cost) {
+				t.Errorf("Expected cost %d, got %s", tt.result.Cost, got)
+			}
+			if got := rec.Header().Get(HeaderRetryAfter); got != tt.expectedRetryAfter {
+				t.Errorf("Expected Retry-After %q, got %q", tt.expectedRetryAfter, got)
+			}
+		})
+	}
+}
+
+type mockAPIKeyRepository struct {
+	keys map[string]models.APIKey
+	err  error // returned by every lookup, e.g. Redis being down
+}
+
+func (m *mockAPIKeyRepository) Lookup(ctx context.Context, rawKey string) (*models.APIKey, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	apiKey, ok := m.keys[rawKey]
+	if !ok {
+		return nil, repository.ErrAPIKeyNotFound
+	}
+	return &apiKey, nil
+}
+
+func (m *mockAPIKeyRepository) Get(ctx context.Context, id string) (*models.APIKey, error) {
+	return nil, repository.ErrAPIKeyNotFound
+}
+
+func (m *mockAPIKeyRepository) Save(ctx context.Context, apiKey *models.APIKey) error {
+	return nil
+}
+
+func (m *mockAPIKeyRepository) List(ctx context.Context) ([]models.APIKey, error) {
+	return nil, nil
+}
+
+func (m *mockAPIKeyRepository) Delete(ctx context.Context, id string) error {
+	return nil
+}
+
+func TestAPIKeyAuth(t *testing.T) {
+	repo := &mockAPIKeyRepository{keys: map[string]models.APIKey{
+		"free-key":    {ID: "k1", Plan: "free", Active: true},
+		"revoked-key": {ID: "k2", Plan: "partner", Active: false},
+	}}
+	plans := map[string]models.Plan{
+		"free": {Name: "free", RequestsPerSecond: 1, Burst: 1, AllowedEndpoints: []string{"/api/flights/search"}},
+	}
+
+	tests := []struct {
+		name           string
+		apiKey         string
+		path           string
+		required       bool
+		expectedStatus int
+		expectedCode   string
+	}{
+		{"anonymous allowed", "", "/api/flights/search", false, http.StatusOK, ""},
+		{"anonymous rejected when required", "", "/api/flights/search", true, http.StatusUnauthorized, "MISSING_API_KEY"},
+		{"unknown key", "nope", "/api/flights/search", false, http.StatusUnauthorized, "INVALID_API_KEY"},
+		{"revoked key", "revoked-key", "/api/flights/search", false, http.StatusUnauthorized, "INVALID_API_KEY"},
+		{"endpoint not in plan", "free-key", "/api/flights/filters", false, http.StatusForbidden, "ENDPOINT_NOT_ALLOWED"},
+		{"valid key", "free-key", "/api/flights/search", false, http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestEcho()
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.apiKey != "" {
+				req.Header.Set(APIKeyHeader, tt.apiKey)
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath(tt.path)
+
+			handler := APIKeyAuth(repo, plans, tt.required, config.RateLimitFailureLocal)(func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			})
+			if err := handler(c); err != nil {
+				e.HTTPErrorHandler(err, c)
+			}
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+			if tt.expectedCode != "" {
+				var errorResp models.ErrorResponse
+				json.Unmarshal(rec.Body.Bytes(), &errorResp)
+				if errorResp.Code != tt.expectedCode {
+					t.Errorf("Expected error code %s, got %s", tt.expectedCode, errorResp.Code)
+				}
+			}
+		})
+	}
+}
+
+func TestAPIKeyAuth_RedisDown(t *testing.T) {
+	repo := &mockAPIKeyRepository{keys: map[string]models.APIKey{
+		"free-key":  {ID: "k1", Plan: "free", Active: true},
+		"other-key": {ID: "k2", Plan: "free", Active: true},
+	}}
+	plans := map[string]models.Plan{"free": {Name: "free", RequestsPerSecond: 1, Burst: 1}}
+
+	tests := []struct {
+		name           string
+		mode           string
+		apiKey         string
+		expectedStatus int
+		expectedKey    string
+	}{
+		{"recently seen key", config.RateLimitFailureClosed, "free-key", http.StatusOK, "k1"},
+		{"unseen key fails closed", config.RateLimitFailureClosed, "other-key", http.StatusServiceUnavailable, ""},
+		{"unseen key is anonymous locally", config.RateLimitFailureLocal, "other-key", http.StatusOK, ""},
+		{"unseen key is anonymous when open", config.RateLimitFailureOpen, "other-key", http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo.err = nil
+			e := newTestEcho()
+			var keyID string
+			handler := APIKeyAuth(repo, plans, false, tt.mode)(func(c echo.Context) error {
+				if apiKey, _, ok := apiKeyFromContext(c); ok {
+					keyID = apiKey.ID
+				}
+				return c.NoContent(http.StatusOK)
+			})
+			serve := func(rawKey string) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(http.MethodPost, "/api/flights/search", nil)
+				req.Header.Set(APIKeyHeader, rawKey)
+				rec := httptest.NewRecorder()
+				c := e.NewContext(req, rec)
+				if err := handler(c); err != nil {
+					e.HTTPErrorHandler(err, c)
+				}
+				return rec
+			}
+
+			// free-key is looked up once while Redis works
+			serve("free-key")
+			repo.err = errors.New("redis: connection refused")
+			keyID = ""
+
+			if rec := serve(tt.apiKey); rec.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+			if keyID != tt.expectedKey {
+				t.Errorf("Expected key %q, got %q", tt.expectedKey, keyID)
+			}
+		})
+	}
+}
+
+func TestDebugAccess(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		adminKey       string
+		expectedStatus int
+		expectedDebug  bool
+	}{
+		{"not asked", "", "", http.StatusOK, false},
+		{"admin key ignored without the flag", "", "secret", http.StatusOK, false},
+		{"asked with admin key", "?debug=true", "secret", http.StatusOK, true},
+		{"asked without admin key", "?debug=true", "", http.StatusUnauthorized, false},
+		{"asked with wrong admin key", "?debug=true", "guess", http.StatusUnauthorized, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestEcho()
+			req := httptest.NewRequest(http.MethodPost, "/api/flights/search"+tt.query, nil)
+			if tt.adminKey != "" {
+				req.Header.Set(AdminKeyHeader, tt.adminKey)
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			debug := false
+			handler := DebugAccess("secret")(func(c echo.Context) error {
+				debug = DebugFromContext(c)
+				return c.NoContent(http.StatusOK)
+			})
+			if err := handler(c); err != nil {
+				e.HTTPErrorHandler(err, c)
+			}
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+			if debug != tt.expectedDebug {
+				t.Errorf("Expected debug %v, got %v", tt.expectedDebug, debug)
+			}
+		})
+	}
+}
+
+func TestLocalLimiter_Allow(t *testing.T) {
+	limiter := NewLocalLimiter()
+
+	for i := 0; i < 3; i++ {
+		if result := limiter.Allow("ip:1.2.3.4", 1, 3, 1); !result.Allowed {
+			t.Fatalf("Expected request %d to be allowed", i+1)
+		}
+	}
+
+	result := limiter.Allow("ip:1.2.3.4", 1, 3, 1)
+	if result.Allowed {
+		t.Error("Expected request beyond capacity to be rejected")
+	}
+	if result.RetryAfter <= 0 {
+		t.Error("Expected positive Retry-After on rejection")
+	}
+
+	// Free requests never consume tokens
+	if result := limiter.Allow("ip:1.2.3.4", 1, 3, 0); !result.Allowed {
+		t.Error("Expected zero-cost request to be allowed")
+	}
+
+	// Other clients have their own bucket
+	if result := limiter.Allow("ip:5.6.7.8", 1, 3, 1); !result.Allowed {
+		t.Error("Expected other client to be allowed")
+	}
+}
+
+func TestRateLimit_CostAboveLimit(t *testing.T) {
+	rsw := &RedisSlidingWindow{limit: 2, window: time.Minute, instances: 1, local: NewLocalLimiter()}
+	c := newTestEcho().NewContext(httptest.NewRequest(http.MethodPost, "/api/flights/search", nil), httptest.NewRecorder())
+
+	// A request costing more than the whole limit is charged the limit
+	result := rsw.checkLocal(c, 5)
+	if !result.Allowed || result.Cost != 2 {
+		t.Errorf("Expected the request to be admitted at cost 2, got %+v", result)
+	}
+	if result := rsw.checkLocal(c, 5); result.Allowed {
+		t.Error("Expected the next request to wait for the limit to refill")
+	}
+}
+
+func TestRateLimit_FallbackWhenRedisDown(t *testing.T) {
+	tests := []struct {
+		name           string
+		mode           string
+		expectedStatus int
+	}{
+		{"fail open", config.RateLimitFailureOpen, http.StatusOK},
+		{"fail closed", config.RateLimitFailureClosed, http.StatusServiceUnavailable},
+		{"local fallback", config.RateLimitFailureLocal, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Setenv("RATE_LIMIT_FAILURE_MODE", tt.mode)
+			defer os.Unsetenv("RATE_LIMIT_FAILURE_MODE")
+
+			// Nothing listens on this port, every Redis call fails
+			rdb := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1"})
+			defer rdb.Close()
+
+			e := newTestEcho()
+			req := httptest.NewRequest(http.MethodGet, "/api/flights/filters", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			handler := NewRedisSlidingWindowRateLimit(rdb)(func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			})
+			if err := handler(c); err != nil {
+				e.HTTPErrorHandler(err, c)
+			}
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+			if tt.mode != config.RateLimitFailureClosed && rec.Header().Get(HeaderRateLimitLimit) != "100" {
+				t.Errorf("Expected local limit 100, got %s", rec.Header().Get(HeaderRateLimitLimit))
+			}
+		})
+	}
+}
+
+func TestRequestCost(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		body         string
+		cacheControl string
+		expectedCost int
+	}{
+		{"filters are free", http.MethodGet, "/api/flights/filters", "", "no-cache", 0},
+		{"one-way search", http.MethodPost, "/api/flights/search", `{"origin":"CGK"}`, "", 1},
+		{"round-trip search", http.MethodPost, "/api/flights/search", `{"origin":"CGK","returnDate":"2025-12-20"}`, "", 2},
+		{"page of a round-trip search", http.MethodPost, "/api/flights/search", `{"returnDate":"2025-12-20","cursor":"abc"}`, "", 1},
+		{"no-cache is not charged", http.MethodPost, "/api/flights/search", `{"origin":"CGK"}`, "no-cache", 1},
+		{"unknown route uses default", http.MethodGet, "/api/other", "", "", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestEcho()
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.cacheControl != "" {
+				req.Header.Set(echo.HeaderCacheControl, tt.cacheControl)
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath(tt.path)
+
+			if cost := requestCost(c, config.DefaultRateLimitCosts); cost != tt.expectedCost {
+				t.Errorf("Expected cost %d, got %d", tt.expectedCost, cost)
+			}
+
+			// The body must still be readable by the handler
+			if body, _ := io.ReadAll(c.Request().Body); string(body) != tt.body {
+				t.Errorf("Expected body %q to be preserved, got %q", tt.body, string(body))
+			}
+		})
+	}
+}
+
+type mockQuotaConsumer struct {
+	usage    *models.Usage
+	err      error
+	released int
+}
+
+func (m *mockQuotaConsumer) ConsumeSearch(ctx context.Context, clientID string, plan *models.Plan) (*models.Usage, error) {
+	return m.usage, m.err
+}
+
+func (m *mockQuotaConsumer) ReleaseSearch(ctx context.Context, clientID string, consumedAt time.Time) error {
+	m.released++
+	return nil
+}
+
+func TestSearchQuota(t *testing.T) {
+	monthEnd := time.Now().Add(72 * time.Hour).UTC().Truncate(time.Second)
+
+	tests := []struct {
+		name           string
+		quota          *mockQuotaConsumer
+		expectedStatus int
+		expectedCode   string
+		body           string
+	}{
+		{"within quota", &mockQuotaConsumer{usage: &models.Usage{}}, http.StatusOK, "", `{"origin":"CGK"}`},
+		{"monthly quota used up", &mockQuotaConsumer{usage: &models.Usage{
+			Monthly:  models.QuotaPeriod{Used: 10, Limit: 10, ResetAt: monthEnd},
+			Exceeded: models.QuotaPeriodMonthly,
+		}}, http.StatusTooManyRequests, "QUOTA_EXCEEDED", `{"origin":"CGK"}`},
+		{"quota store unavailable", &mockQuotaConsumer{err: fmt.Errorf("SERVICE_ERROR: Failed to update search quota")}, http.StatusOK, "", ""},
+		{"next page is free", &mockQuotaConsumer{usage: &models.Usage{
+			Monthly:  models.QuotaPeriod{Used: 10, Limit: 10, ResetAt: monthEnd},
+			Exceeded: models.QuotaPeriodMonthly,
+		}}, http.StatusOK, "", `{"cursor":"eyJzIjoiYWJjIiwibyI6MjAsImwiOjIwfQ"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestEcho()
+			req := httptest.NewRequest(http.MethodPost, "/api/flights/search", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			handler := SearchQuota(tt.quota)(func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			})
+			if err := handler(c); err != nil {
+				e.HTTPErrorHandler(err, c)
+			}
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+			if tt.expectedCode == "" {
+				return
+			}
+
+			var errorResp models.ErrorResponse
+			json.Unmarshal(rec.Body.Bytes(), &errorResp)
+			if errorResp.Code != tt.expectedCode {
+				t.Errorf("Expected error code %s, got %s", tt.expectedCode, errorResp.Code)
+			}
+			if errorResp.ResetAt == nil || !errorResp.ResetAt.Equal(monthEnd) {
+				t.Errorf("Expected reset_at %v, got %v", monthEnd, errorResp.ResetAt)
+			}
+			if rec.Header().Get(HeaderRetryAfter) == "" {
+				t.Error("Expected Retry-After header")
+			}
+		})
+	}
+}
+
+func TestSearchQuota_FailedSearchIsGivenBack(t *testing.T) {
+	tests := []struct {
+		name     string
+		handler  echo.HandlerFunc
+		released int
+	}{
+		{"successful search", func(c echo.Context) error {
+			return c.NoContent(http.StatusOK)
+		}, 0},
+		{"validation error", func(c echo.Context) error {
+			return apperror.New(apperror.CodeValidation, "Invalid request")
+		}, 1},
+		{"all providers down", func(c echo.Context) error {
+			return apperror.New(apperror.CodeService, "All providers failed")
+		}, 1},
+		{"error written by the handler", func(c echo.Context) error {
+			return c.NoContent(http.StatusServiceUnavailable)
+		}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quota := &mockQuotaConsumer{usage: &models.Usage{}}
+			e := newTestEcho()
+			req := httptest.NewRequest(http.MethodPost, "/api/flights/search", strings.NewReader(`{"origin":"CGK"}`))
+			c := e.NewContext(req, httptest.NewRecorder())
+
+			SearchQuota(quota)(tt.handler)(c)
+
+			if quota.released != tt.released {
+				t.Errorf("Expected %d released searches, got %d", tt.released, quota.released)
+			}
+		})
+	}
+}
+
+type mockTenantRepository struct {
+	tenants map[string]models.Tenant
+	err     error
+}
+
+func (m *mockTenantRepository) Get(ctx context.Context, id string) (*models.Tenant, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	tenant, ok := m.tenants[id]
+	if !ok {
+		return nil, repository.ErrTenantNotFound
+	}
+	return &tenant, nil
+}
+
+func (m *mockTenantRepository) Save(ctx context.Context, tenant *models.Tenant) error { return nil }
+
+func (m *mockTenantRepository) List(ctx context.Context) ([]models.Tenant, error) { return nil, nil }
+
+func (m *mockTenantRepository) Delete(ctx context.Context, id string) error { return nil }
+
+func TestTenantResolver(t *testing.T) {
+	repo := &mockTenantRepository{tenants: map[string]models.Tenant{
+		"acme": {ID: "acme", Name: "Acme Travel"},
+	}}
+
+	tests := []struct {
+		name           string
+		apiKey         *models.APIKey
+		expectedStatus int
+		expectedTenant string
+	}{
+		{"anonymous", nil, http.StatusOK, ""},
+		{"key without tenant", &models.APIKey{ID: "k1"}, http.StatusOK, ""},
+		{"key with tenant", &models.APIKey{ID: "k2", TenantID: "acme"}, http.StatusOK, "acme"},
+		{"key with unknown tenant", &models.APIKey{ID: "k3", TenantID: "gone"}, http.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestEcho()
+			req := httptest.NewRequest(http.MethodPost, "/api/flights/search", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			if tt.apiKey != nil {
+				c.Set(ContextKeyAPIKey, tt.apiKey)
+				c.Set(ContextKeyPlan, models.Plan{Name: "free"})
+			}
+
+			var tenantID string
+			handler := TenantResolver(repo, config.RateLimitFailureLocal)(func(c echo.Context) error {
+				if tenant := TenantFromContext(c); tenant != nil {
+					tenantID = tenant.ID
+				}
+				return c.NoContent(http.StatusOK)
+			})
+			if err := handler(c); err != nil {
+				e.HTTPErrorHandler(err, c)
+			}
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+			if tenantID != tt.expectedTenant {
+				t.Errorf("Expected tenant %q, got %q", tt.expectedTenant, tenantID)
+			}
+		})
+	}
+}
+
+func TestTenantResolver_RedisDown(t *testing.T) {
+	tests := []struct {
+		name           string
+		mode           string
+		tenantID       string
+		expectedStatus int
+		expectedTenant string
+	}{
+		{"recently loaded tenant", config.RateLimitFailureClosed, "acme", http.StatusOK, "acme"},
+		{"unloaded tenant fails closed", config.RateLimitFailureClosed, "globex", http.StatusServiceUnavailable, ""},
+		{"unloaded tenant is skipped locally", config.RateLimitFailureLocal, "globex", http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockTenantRepository{tenants: map[string]models.Tenant{
+				"acme":   {ID: "acme", Name: "Acme Travel"},
+				"globex": {ID: "globex", Name: "Globex"},
+			}}
+			e := newTestEcho()
+			var tenantID string
+			handler := TenantResolver(repo, tt.mode)(func(c echo.Context) error {
+				if tenant := TenantFromContext(c); tenant != nil {
+					tenantID = tenant.ID
+				}
+				return c.NoContent(http.StatusOK)
+			})
+			serve := func(tenant string) *httptest.ResponseRecorder {
+				rec := httptest.NewRecorder()
+				c := e.NewContext(httptest.NewRequest(http.MethodPost, "/api/flights/search", nil), rec)
+				c.Set(ContextKeyAPIKey, &models.APIKey{ID: "k1", TenantID: tenant})
+				c.Set(ContextKeyPlan, models.Plan{Name: "free"})
+				if err := handler(c); err != nil {
+					e.HTTPErrorHandler(err, c)
+				}
+				return rec
+			}
+
+			// acme is loaded once while Redis works
+			serve("acme")
+			repo.err = errors.New("redis: connection refused")
+			tenantID = ""
+
+			if rec := serve(tt.tenantID); rec.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+			if tenantID != tt.expectedTenant {
+				t.Errorf("Expected tenant %q, got %q", tt.expectedTenant, tenantID)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"context"
+	"flight-aggregator/internal/apperror"
+	"flight-aggregator/internal/models"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// QuotaConsumer counts searches against a client's quotas. The quota usecase
+// implements it; middleware only needs these two calls.
+type QuotaConsumer interface {
+	// ConsumeSearch counts one search, the usage has Exceeded set when it was rejected
+	ConsumeSearch(ctx context.Context, clientID string, plan *models.Plan) (*models.Usage, error)
+	// ReleaseSearch gives back a search counted at consumedAt
+	ReleaseSearch(ctx context.Context, clientID string, consumedAt time.Time) error
+}
+
+// SearchQuota enforces the daily and monthly search quotas of the client. Quotas
+// are a billing concern, so a Redis outage lets searches through rather than
+// blocking every client. Reading another page of a search is not a new search,
+// and a search that fails is given back.
+func SearchQuota(quota QuotaConsumer) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if readRequestShape(c).Page {
+				return next(c)
+			}
+			clientID, plan := QuotaClient(c)
+
+			ctx, cancel := context.WithTimeout(c.Request().Context(), redisCallTimeout)
+			defer cancel()
+
+			consumedAt := time.Now()
+			usage, err := quota.ConsumeSearch(ctx, clientID, plan)
+			if err != nil {
+				log.Printf("Search quota not enforced for %s: %v", clientID, err)
+				return next(c)
+			}
+
+			if usage.Exceeded != "" {
+				period := usage.Daily
+				if usage.Exceeded == models.QuotaPeriodMonthly {
+					period = usage.Monthly
+				}
+				resetAt := period.ResetAt
+				retryAfter := int(time.Until(resetAt).Seconds()) + 1
+				c.Response().Header().Set(HeaderRetryAfter, strconv.Itoa(retryAfter))
+
+				quotaErr := apperror.Newf(apperror.CodeQuotaExceeded, "Your %s search quota of %d is used up", usage.Exceeded, period.Limit)
+				quotaErr.ResetAt = &resetAt
+				return quotaErr
+			}
+
+			err = next(c)
+			if err != nil || c.Response().Status >= http.StatusBadRequest {
+				releaseSearch(c, quota, clientID, consumedAt)
+			}
+			return err
+		}
+	}
+}
+
+// releaseSearch gives back a counted search, even if the client has gone away
+func releaseSearch(c echo.Context, quota QuotaConsumer, clientID string, consumedAt time.Time) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(c.Request().Context()), redisCallTimeout)
+	defer cancel()
+
+	if err := quota.ReleaseSearch(ctx, clientID, consumedAt); err != nil {
+		log.Printf("Failed search not given back to %s: %v", clientID, err)
+	}
+}
+
+// QuotaClient identifies the client quotas are counted for: the API key when
+// authenticated, the IP address otherwise
+func QuotaClient(c echo.Context) (string, *models.Plan) {
+	if apiKey, plan, ok := apiKeyFromContext(c); ok {
+		return "key:" + apiKey.ID, &plan
+	}
+	return "ip:" + clientIP(c), nil
+}
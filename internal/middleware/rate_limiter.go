@@ -2,74 +2,178 @@ package middleware
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"flight-aggregator/internal/apperror"
 	"flight-aggregator/internal/config"
 	"flight-aggregator/internal/models"
 	"fmt"
 	"log"
-	"net/http"
+	"math"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/labstack/echo/v4"
 )
 
+// Rate limit response headers
+const (
+	HeaderRateLimitLimit     = "X-RateLimit-Limit"
+	HeaderRateLimitRemaining = "X-RateLimit-Remaining"
+	HeaderRateLimitReset     = "X-RateLimit-Reset"
+	HeaderRateLimitCost      = "X-RateLimit-Cost"
+	HeaderRetryAfter         = "Retry-After"
+)
+
+// redisCallTimeout bounds each limiter call so a dead Redis doesn't stall requests
+const redisCallTimeout = 250 * time.Millisecond
+
 type RedisSlidingWindow struct {
 	client *redis.Client
 	limit  int
 	window time.Duration
+
+	failureMode string
+	instances   int
+	local       *LocalLimiter
+	health      *redisHealth
+	costs       config.RateLimitCosts
+}
+
+// redisHealth tracks whether Redis is usable. While unhealthy, Redis is only
+// probed again once per recheck interval.
+type redisHealth struct {
+	mu        sync.Mutex
+	healthy   bool
+	lastCheck time.Time
+	recheck   time.Duration
+}
+
+// RateLimitResult is the outcome of a single rate limit check
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Time
+	RetryAfter time.Duration
+	Cost       int
 }
 
-func NewRedisSlidingWindowRateLimit() echo.MiddlewareFunc {
+// slidingWindowScript trims expired entries, counts the window and only records
+// the request when it is allowed, all in one atomic step. A request of cost N
+// occupies N entries. It returns {allowed, count, oldest entry score in ms}.
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+local member = ARGV[4]
+local cost = tonumber(ARGV[5])
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+local count = redis.call('ZCARD', key)
+local allowed = 0
+if count + cost <= limit then
+	for i = 1, cost do
+		redis.call('ZADD', key, now, member .. ':' .. i)
+	end
+	count = count + cost
+	allowed = 1
+end
+redis.call('PEXPIRE', key, window)
+
+local oldest = now
+local first = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+if first[2] then
+	oldest = tonumber(first[2])
+end
+return {allowed, count, oldest}
+`)
+
+// planLimitScript enforces an API key plan: a daily cap and a token bucket refilled
+// at requestsPerSecond with room for burst requests. Both are charged the request
+// cost. It returns
+// {allowed, tokens left, used today, daily limit hit}.
+var planLimitScript = redis.NewScript(`
+local bucket = KEYS[1]
+local daily = KEYS[2]
+local rate = tonumber(ARGV[1])
+local capacity = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local day_limit = tonumber(ARGV[4])
+local day_ttl = tonumber(ARGV[5])
+local cost = tonumber(ARGV[6])
+
+local used = tonumber(redis.call('GET', daily) or '0')
+if day_limit > 0 and used + cost > day_limit then
+	return {0, 0, used, 1}
+end
+
+local state = redis.call('HMGET', bucket, 'tokens', 'ts')
+local tokens = tonumber(state[1]) or capacity
+local ts = tonumber(state[2]) or now
+tokens = math.min(capacity, tokens + (now - ts) * rate / 1000)
+
+local allowed = 0
+if tokens >= cost then
+	tokens = tokens - cost
+	allowed = 1
+	if cost > 0 then
+		used = redis.call('INCRBY', daily, cost)
+		if used == cost then
+			redis.call('PEXPIRE', daily, day_ttl)
+		end
+	end
+end
+
+redis.call('HSET', bucket, 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', bucket, math.ceil(capacity / rate * 1000) + 1000)
+return {allowed, math.floor(tokens), used, 0}
+`)
+
+func NewRedisSlidingWindowRateLimit(rdb *redis.Client) echo.MiddlewareFunc {
 	cfg := config.MustLoad()
-	
-	// Parse Redis URL if it contains credentials
-	var rdb *redis.Client
-	if len(cfg.RedisAddr) > 8 && cfg.RedisAddr[:8] == "redis://" {
-		opt, err := redis.ParseURL(cfg.RedisAddr)
-		if err != nil {
-			log.Printf("Failed to parse Redis URL: %v, falling back to simple connection", err)
-			rdb = redis.NewClient(&redis.Options{
-				Addr: "localhost:6379",
-				DB:   0,
-			})
-		} else {
-			rdb = redis.NewClient(opt)
-		}
-	} else {
-		rdb = redis.NewClient(&redis.Options{
-			Addr: cfg.RedisAddr,
-			DB:   0,
-		})
+	costs, err := config.LoadRateLimitCosts(cfg.RateLimitCostsFile)
+	if err != nil {
+		log.Printf("Failed to load rate limit costs: %v, using defaults", err)
+		costs = config.DefaultRateLimitCosts
 	}
 
 	rsw := &RedisSlidingWindow{
-		client: rdb,
-		limit:  cfg.RateLimitCount,
-		window: cfg.RateLimitWindow,
+		client:      rdb,
+		limit:       cfg.RateLimitCount,
+		window:      cfg.RateLimitWindow,
+		failureMode: cfg.RateLimitFailureMode,
+		instances:   cfg.RateLimitInstances,
+		local:       NewLocalLimiter(),
+		health:      &redisHealth{healthy: true, recheck: cfg.RateLimitRedisRecheck},
+		costs:       costs,
 	}
 
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			// Check Rate Limit Using IP
-			clientIP := c.RealIP()
-			if clientIP == "" {
-				clientIP = c.Request().RemoteAddr
-			}
-
-			allowed, err := rsw.Allow(c.Request().Context(), clientIP)
+			cost := requestCost(c, rsw.costs)
+			result, err := rsw.check(c, cost)
 			if err != nil {
-				// If Redis fails, allow the request (fail open)
-				return next(c)
+				switch rsw.failureMode {
+				case config.RateLimitFailureOpen:
+					// Every request passes, the local limiter only keeps the headers meaningful
+					result = rsw.checkLocal(c, cost)
+					result.Allowed = true
+					setRateLimitHeaders(c, result)
+					return next(c)
+				case config.RateLimitFailureClosed:
+					return apperror.Wrap(apperror.CodeService, err, "Rate limiter is temporarily unavailable")
+				default:
+					result = rsw.checkLocal(c, cost)
+				}
 			}
 
-			if !allowed {
-				errorResp := models.ErrorResponse{
-					Status:  "error",
-					Code:    "RATE_LIMIT_EXCEEDED",
-					Message: "Too many requests, please try again later",
-				}
-				return c.JSON(http.StatusTooManyRequests, errorResp)
+			setRateLimitHeaders(c, result)
+			if !result.Allowed {
+				return apperror.New(apperror.CodeRateLimitExceeded, "")
 			}
 
 			return next(c)
@@ -77,26 +181,208 @@ func NewRedisSlidingWindowRateLimit() echo.MiddlewareFunc {
 	}
 }
 
-func (rsw *RedisSlidingWindow) Allow(ctx context.Context, key string) (bool, error) {
+// check applies the Redis limits. It returns an error while Redis is unhealthy.
+func (rsw *RedisSlidingWindow) check(c echo.Context, cost int) (*RateLimitResult, error) {
+	if !rsw.health.available() {
+		return nil, fmt.Errorf("redis unavailable")
+	}
+
+	// A client going away must not count as Redis failing
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(c.Request().Context()), redisCallTimeout)
+	defer cancel()
+
+	var result *RateLimitResult
+	var err error
+	if apiKey, plan, ok := apiKeyFromContext(c); ok {
+		// Authenticated clients are limited by their plan
+		result, err = rsw.AllowPlan(ctx, apiKey.ID, plan, cost)
+	} else {
+		// Anonymous clients share the global limit per IP
+		result, err = rsw.Allow(ctx, clientIP(c), cost)
+	}
+	rsw.health.report(err)
+	return result, err
+}
+
+// checkLocal applies this instance's share of the limits in memory. Daily plan
+// caps are not enforced locally.
+func (rsw *RedisSlidingWindow) checkLocal(c echo.Context, cost int) *RateLimitResult {
+	share := float64(rsw.instances)
+
+	if apiKey, plan, ok := apiKeyFromContext(c); ok {
+		capacity := int(math.Max(1, math.Ceil(float64(planCapacity(plan))/share)))
+		return rsw.local.Allow("key:"+apiKey.ID, plan.RequestsPerSecond/share, capacity, admissibleCost(cost, capacity))
+	}
+
+	capacity := int(math.Max(1, math.Ceil(float64(rsw.limit)/share)))
+	rate := float64(capacity) / rsw.window.Seconds()
+	return rsw.local.Allow("ip:"+clientIP(c), rate, capacity, admissibleCost(cost, capacity))
+}
+
+// admissibleCost caps a request's cost at the limit it is charged against, so a
+// route costing more than a whole limit is still admitted once the limit is free
+func admissibleCost(cost, limit int) int {
+	if limit > 0 && cost > limit {
+		return limit
+	}
+	return cost
+}
+
+func clientIP(c echo.Context) string {
+	if ip := c.RealIP(); ip != "" {
+		return ip
+	}
+	return c.Request().RemoteAddr
+}
+
+// available reports whether Redis should be called, letting one probe through
+// per recheck interval while unhealthy
+func (rh *redisHealth) available() bool {
+	rh.mu.Lock()
+	defer rh.mu.Unlock()
+
+	if rh.healthy {
+		return true
+	}
+	if time.Since(rh.lastCheck) >= rh.recheck {
+		rh.lastCheck = time.Now()
+		return true
+	}
+	return false
+}
+
+func (rh *redisHealth) report(err error) {
+	rh.mu.Lock()
+	defer rh.mu.Unlock()
+
+	if err != nil {
+		if rh.healthy {
+			log.Printf("Redis rate limiter unavailable, switching to fallback: %v", err)
+		}
+		rh.healthy = false
+		rh.lastCheck = time.Now()
+		return
+	}
+	if !rh.healthy {
+		log.Printf("Redis rate limiter recovered")
+	}
+	rh.healthy = true
+}
+
+func (rsw *RedisSlidingWindow) Allow(ctx context.Context, key string, cost int) (*RateLimitResult, error) {
 	now := time.Now().UnixMilli()
-	windowStart := now - rsw.window.Milliseconds()
 	redisKey := fmt.Sprintf("rate_limit:%s", key)
+	cost = admissibleCost(cost, rsw.limit)
+
+	// Members must be unique, two requests in the same millisecond are still two requests
+	member := strconv.FormatInt(now, 10) + "-" + randomSuffix()
+
+	values, err := slidingWindowScript.Run(ctx, rsw.client, []string{redisKey},
+		now, rsw.window.Milliseconds(), rsw.limit, member, cost).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != 3 {
+		return nil, fmt.Errorf("unexpected rate limit script result: %v", values)
+	}
 
-	// Remove old entries and count current requests
-	pipe := rsw.client.Pipeline()
-	pipe.ZRemRangeByScore(ctx, redisKey, "0", strconv.FormatInt(windowStart, 10))
-	pipe.ZCard(ctx, redisKey)
-	pipe.ZAdd(ctx, redisKey, &redis.Z{Score: float64(now), Member: now})
-	pipe.Expire(ctx, redisKey, rsw.window)
+	allowed := values[0] == 1
+	count := int(values[1])
+	reset := time.UnixMilli(values[2] + rsw.window.Milliseconds())
 
-	results, err := pipe.Exec(ctx)
+	result := &RateLimitResult{
+		Allowed:   allowed,
+		Limit:     rsw.limit,
+		Remaining: rsw.limit - count,
+		Reset:     reset,
+		Cost:      cost,
+	}
+	if result.Remaining < 0 {
+		result.Remaining = 0
+	}
+	if !allowed {
+		result.RetryAfter = time.Until(reset)
+	}
+	return result, nil
+}
+
+// AllowPlan applies the token bucket and daily cap of an API key plan
+func (rsw *RedisSlidingWindow) AllowPlan(ctx context.Context, keyID string, plan models.Plan, cost int) (*RateLimitResult, error) {
+	now := time.Now()
+	capacity := planCapacity(plan)
+	cost = admissibleCost(admissibleCost(cost, capacity), plan.RequestsPerDay)
+
+	// Daily caps roll over at midnight UTC
+	utcNow := now.UTC()
+	dayEnd := time.Date(utcNow.Year(), utcNow.Month(), utcNow.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+	bucketKey := fmt.Sprintf("rate_limit:key:%s", keyID)
+	dailyKey := fmt.Sprintf("rate_limit:day:%s:%s", keyID, utcNow.Format("20060102"))
+
+	values, err := planLimitScript.Run(ctx, rsw.client, []string{bucketKey, dailyKey},
+		plan.RequestsPerSecond, capacity, now.UnixMilli(), plan.RequestsPerDay, time.Until(dayEnd).Milliseconds()+1000, cost).Int64Slice()
 	if err != nil {
-		return false, err
+		return nil, err
+	}
+	if len(values) != 4 {
+		return nil, fmt.Errorf("unexpected plan limit script result: %v", values)
+	}
+
+	if values[3] == 1 {
+		return &RateLimitResult{
+			Allowed:    false,
+			Limit:      plan.RequestsPerDay,
+			Remaining:  0,
+			Reset:      dayEnd,
+			RetryAfter: time.Until(dayEnd),
+			Cost:       cost,
+		}, nil
 	}
 
-	count := results[1].(*redis.IntCmd).Val()
-	return count < int64(rsw.limit), nil
+	allowed := values[0] == 1
+	tokens := int(values[1])
+	refill := time.Duration(float64(capacity-tokens) / plan.RequestsPerSecond * float64(time.Second))
+	result := &RateLimitResult{
+		Allowed:   allowed,
+		Limit:     capacity,
+		Remaining: tokens,
+		Reset:     now.Add(refill),
+		Cost:      cost,
+	}
+	if !allowed {
+		result.RetryAfter = time.Duration(float64(cost-tokens) / plan.RequestsPerSecond * float64(time.Second))
+	}
+	return result, nil
 }
 
+// planCapacity is the token bucket size of a plan, at least one second of traffic
+func planCapacity(plan models.Plan) int {
+	capacity := plan.Burst
+	if minimum := int(math.Ceil(plan.RequestsPerSecond)); capacity < minimum {
+		capacity = minimum
+	}
+	return capacity
+}
 
+// setRateLimitHeaders tells clients how much quota is left and when to come back
+func setRateLimitHeaders(c echo.Context, result *RateLimitResult) {
+	header := c.Response().Header()
+	header.Set(HeaderRateLimitLimit, strconv.Itoa(result.Limit))
+	header.Set(HeaderRateLimitRemaining, strconv.Itoa(result.Remaining))
+	header.Set(HeaderRateLimitReset, strconv.FormatInt(result.Reset.Unix(), 10))
+	header.Set(HeaderRateLimitCost, strconv.Itoa(result.Cost))
+	if !result.Allowed {
+		retryAfter := int(math.Ceil(result.RetryAfter.Seconds()))
+		if retryAfter < 1 {
+			retryAfter = 1
+		}
+		header.Set(HeaderRetryAfter, strconv.Itoa(retryAfter))
+	}
+}
 
+func randomSuffix() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"flight-aggregator/internal/config"
+	"io"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// requestShape describes how much provider work a request triggers
+type requestShape struct {
+	Legs int
+	Page bool // reads a page of a stored search through a cursor
+}
+
+// requestCost is the number of rate-limit units a request consumes. Free routes
+// stay free whatever their shape.
+func requestCost(c echo.Context, costs config.RateLimitCosts) int {
+	cost := costs.RouteCost(c.Request().Method, c.Path())
+	if cost <= 0 {
+		return 0
+	}
+
+	shape := readRequestShape(c)
+	if shape.Page {
+		return costs.PageCost()
+	}
+	return cost + (shape.Legs-1)*costs.ExtraLeg
+}
+
+// readRequestShape inspects the request without consuming its body
+func readRequestShape(c echo.Context) requestShape {
+	shape := requestShape{Legs: 1}
+	req := c.Request()
+
+	if req.Method != http.MethodPost || req.Body == nil {
+		return shape
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return shape
+	}
+
+	var fields struct {
+		ReturnDate *string `json:"returnDate"`
+		Cursor     string  `json:"cursor"`
+	}
+	if json.Unmarshal(body, &fields) != nil {
+		return shape
+	}
+	if fields.ReturnDate != nil && *fields.ReturnDate != "" {
+		shape.Legs = 2
+	}
+	shape.Page = fields.Cursor != ""
+	return shape
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"context"
+	"flight-aggregator/internal/apperror"
+	"flight-aggregator/internal/config"
+	"flight-aggregator/internal/models"
+	"flight-aggregator/internal/repository"
+	"log"
+
+	"github.com/labstack/echo/v4"
+)
+
+const ContextKeyTenant = "tenant"
+
+// TenantResolver loads the tenant of the authenticated API key. It must run after
+// APIKeyAuth. Anonymous requests and keys without a tenant get no tenant. While
+// Redis is unavailable a tenant loaded recently is served from memory; without one
+// the request is rejected in the closed failure mode and searched without a tenant
+// otherwise.
+func TenantResolver(repo repository.TenantRepository, failureMode string) echo.MiddlewareFunc {
+	tenants := newLookupCache[models.Tenant]()
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			apiKey, _, ok := apiKeyFromContext(c)
+			if !ok || apiKey.TenantID == "" {
+				return next(c)
+			}
+
+			ctx, cancel := context.WithTimeout(c.Request().Context(), redisCallTimeout)
+			tenant, err := repo.Get(ctx, apiKey.TenantID)
+			cancel()
+			if err == repository.ErrTenantNotFound {
+				log.Printf("API key %s references unknown tenant %s", apiKey.ID, apiKey.TenantID)
+				return apperror.New(apperror.CodeInvalidAPIKey, "API key has no valid tenant")
+			}
+			if err == nil {
+				tenants.set(apiKey.TenantID, *tenant)
+			} else {
+				cached, ok := tenants.get(apiKey.TenantID)
+				if !ok {
+					log.Printf("Failed to load tenant %s: %v", apiKey.TenantID, err)
+					if failureMode == config.RateLimitFailureClosed {
+						return apperror.Wrap(apperror.CodeService, err, "Unable to load tenant configuration")
+					}
+					return next(c)
+				}
+				tenant = &cached
+			}
+
+			c.Set(ContextKeyTenant, tenant)
+			return next(c)
+		}
+	}
+}
+
+// TenantFromContext returns the tenant set by TenantResolver, nil when there is none
+func TenantFromContext(c echo.Context) *models.Tenant {
+	tenant, _ := c.Get(ContextKeyTenant).(*models.Tenant)
+	return tenant
+}
@@ -1,9 +1,9 @@
 package middleware
 
 import (
-	"flight-aggregator/internal/models"
-	"net/http"
+	"flight-aggregator/internal/apperror"
 	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 )
@@ -13,6 +13,8 @@ const TracerIDHeader = "X-Tracer-ID"
 func TracerMiddleware() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
+			c.Set(ContextKeyStartTime, time.Now())
+
 			// Skip tracer requirement for Swagger/docs endpoints
 			path := c.Request().URL.Path
 			if path == "/" || path == "/docs" || path == "/docs/" || 
@@ -22,12 +24,7 @@ func TracerMiddleware() echo.MiddlewareFunc {
 			
 			tracerID := c.Request().Header.Get(TracerIDHeader)
 			if tracerID == "" {
-				errorResp := models.ErrorResponse{
-					Status:  "error",
-					Code:    "MISSING_TRACER_ID",
-					Message: "X-Tracer-ID header is required",
-				}
-				return c.JSON(http.StatusBadRequest, errorResp)
+				return apperror.New(apperror.CodeMissingTracerID, "")
 			}
 			
 			c.Response().Header().Set(TracerIDHeader, tracerID)
@@ -0,0 +1,72 @@
+package models
+
+import "time"
+
+// Plan defines the rate limits granted to an API key
+type Plan struct {
+	Name              string   `json:"name"`
+	RequestsPerSecond float64  `json:"requestsPerSecond"`
+	RequestsPerDay    int      `json:"requestsPerDay"`
+	Burst             int      `json:"burst"`
+	AllowedEndpoints  []string `json:"allowedEndpoints"` // route paths, empty means every endpoint
+	SearchesPerDay    int      `json:"searchesPerDay"`   // contractual quota, 0 means unlimited
+	SearchesPerMonth  int      `json:"searchesPerMonth"` // contractual quota, 0 means unlimited
+}
+
+// AllowsEndpoint reports whether the plan grants access to a route path
+func (p Plan) AllowsEndpoint(path string) bool {
+	if len(p.AllowedEndpoints) == 0 {
+		return true
+	}
+	for _, endpoint := range p.AllowedEndpoints {
+		if endpoint == path {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKey is a stored API key. Only the hash of the raw key is persisted.
+type APIKey struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Plan      string    `json:"plan"`
+	TenantID  string    `json:"tenantId,omitempty"`
+	KeyHash   string    `json:"-"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name     string `json:"name" validate:"required"`
+	Plan     string `json:"plan" validate:"required"`
+	TenantID string `json:"tenantId"` // optional, keys without a tenant use the defaults
+}
+
+// CreateAPIKeyResponse is the only time the raw key is returned
+type CreateAPIKeyResponse struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// Quota periods
+const (
+	QuotaPeriodDaily   = "daily"
+	QuotaPeriodMonthly = "monthly"
+)
+
+// QuotaPeriod is the search consumption of a client in one period
+type QuotaPeriod struct {
+	Used    int       `json:"used"`
+	Limit   int       `json:"limit"` // 0 means unlimited
+	ResetAt time.Time `json:"reset_at"`
+}
+
+// Usage reports a client's long-horizon search quota consumption
+type Usage struct {
+	Client   string      `json:"client"`
+	Plan     string      `json:"plan"`
+	Daily    QuotaPeriod `json:"daily"`
+	Monthly  QuotaPeriod `json:"monthly"`
+	Exceeded string      `json:"exceeded,omitempty"` // period that rejected the last search
+}
@@ -0,0 +1,78 @@
+package models
+
+import "time"
+
+// Departure buckets, named parts of the day in airport local time
+const (
+	BucketEarlyMorning = "early_morning"
+	BucketMorning      = "morning"
+	BucketAfternoon    = "afternoon"
+	BucketEvening      = "evening"
+	BucketRedEye       = "red_eye"
+)
+
+// DepartureBucket is a named part of the day
+type DepartureBucket struct {
+	Name   string     `json:"name"`
+	Window TimeWindow `json:"window"`
+}
+
+// DepartureBuckets cover the day without overlapping, in the order they are shown.
+// Arrival times are bucketed the same way.
+var DepartureBuckets = []DepartureBucket{
+	{Name: BucketEarlyMorning, Window: TimeWindow{From: "04:00", To: "07:59"}},
+	{Name: BucketMorning, Window: TimeWindow{From: "08:00", To: "11:59"}},
+	{Name: BucketAfternoon, Window: TimeWindow{From: "12:00", To: "17:59"}},
+	{Name: BucketEvening, Window: TimeWindow{From: "18:00", To: "21:59"}},
+	{Name: BucketRedEye, Window: TimeWindow{From: "22:00", To: "03:59"}},
+}
+
+// DepartureBucketOf returns the name of the bucket the local time of day of t falls in
+func DepartureBucketOf(t time.Time) string {
+	for _, bucket := range DepartureBuckets {
+		if bucket.Window.Contains(t) {
+			return bucket.Name
+		}
+	}
+	return ""
+}
+
+func isDepartureBucket(name string) bool {
+	for _, bucket := range DepartureBuckets {
+		if bucket.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func departureBucketNames() []string {
+	names := make([]string, len(DepartureBuckets))
+	for i, bucket := range DepartureBuckets {
+		names[i] = bucket.Name
+	}
+	return names
+}
+
+// Facets describe the flights of a search so a UI only offers filter options that
+// match something
+type Facets struct {
+	Airlines       []FacetCount  `json:"airlines"`
+	PriceRange     PriceRange    `json:"priceRange"`
+	DurationRange  DurationRange `json:"durationRange"`
+	Stops          []StopsCount  `json:"stops"`
+	DepartureTimes []FacetCount  `json:"departureTimes"` // DepartureBuckets in day order
+	Aircraft       []FacetCount  `json:"aircraft"`
+}
+
+// FacetCount is a filter value and how many flights have it
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// StopsCount is how many flights make a number of stops
+type StopsCount struct {
+	Stops int `json:"stops"`
+	Count int `json:"count"`
+}
@@ -0,0 +1,72 @@
+package models
+
+import "strings"
+
+// Amenities a flight can offer, providers' own names are normalized to these
+const (
+	AmenityWifi          = "wifi"
+	AmenityMeal          = "meal"
+	AmenityPower         = "power"
+	AmenityEntertainment = "entertainment"
+	AmenitySnack         = "snack"
+	AmenityBeverage      = "beverage"
+)
+
+// Amenities are the accepted values of FilterOptions.Amenities
+var Amenities = []string{AmenityWifi, AmenityMeal, AmenityPower, AmenityEntertainment, AmenitySnack, AmenityBeverage}
+
+// Aircraft bodies
+const (
+	AircraftNarrowBody = "narrow_body"
+	AircraftWideBody   = "wide_body"
+)
+
+// wideBodyFamilies are matched against aircraft names, any other known family is
+// narrow-body
+var wideBodyFamilies = []string{"A330", "A340", "A350", "A380", "747", "767", "777", "787"}
+
+var narrowBodyFamilies = []string{"A220", "A318", "A319", "A320", "A321", "737", "757"}
+
+// AircraftBody returns whether an aircraft is narrow or wide body, empty when its
+// family is not known
+func AircraftBody(aircraft string) string {
+	name := strings.ToUpper(aircraft)
+	for _, family := range wideBodyFamilies {
+		if strings.Contains(name, family) {
+			return AircraftWideBody
+		}
+	}
+	for _, family := range narrowBodyFamilies {
+		if strings.Contains(name, family) {
+			return AircraftNarrowBody
+		}
+	}
+	return ""
+}
+
+// StandardBagKg is the weight a checked piece counts for when a provider gives its
+// allowance in pieces
+const StandardBagKg = 23
+
+// BaggageAllowance is the baggage a fare includes. Providers give it in kg or in
+// pieces, a zero field is not included or not reported.
+type BaggageAllowance struct {
+	CabinKg       int `json:"cabinKg,omitempty"`
+	CabinPieces   int `json:"cabinPieces,omitempty"`
+	CheckedKg     int `json:"checkedKg,omitempty"`
+	CheckedPieces int `json:"checkedPieces,omitempty"`
+}
+
+// CheckedAllowanceKg returns the checked allowance in kg, counting pieces as
+// StandardBagKg each when no weight is given
+func (b BaggageAllowance) CheckedAllowanceKg() int {
+	if b.CheckedKg > 0 {
+		return b.CheckedKg
+	}
+	return b.CheckedPieces * StandardBagKg
+}
+
+// IncludesChecked reports whether the fare includes any checked baggage
+func (b BaggageAllowance) IncludesChecked() bool {
+	return b.CheckedAllowanceKg() > 0
+}
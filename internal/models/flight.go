@@ -1,27 +1,90 @@
 package models
 
 import (
+	"flight-aggregator/internal/apperror"
 	"time"
-	"github.com/go-playground/validator/v10"
 )
 
 type SearchRequest struct {
-	Origin        string  `json:"origin" validate:"required"`
-	Destination   string  `json:"destination" validate:"required"`
-	DepartureDate string  `json:"departureDate" validate:"required"`
-	ReturnDate    *string `json:"returnDate"`
-	Passengers    int     `json:"passengers" validate:"required,min=1"`
-	CabinClass    string  `json:"cabinClass" validate:"required"`
+	Origin        string  `json:"origin" validate:"required,iata"`
+	Destination   string  `json:"destination" validate:"required,iata,nefield=Origin"`
+	DepartureDate string  `json:"departureDate" validate:"required,datetime=2006-01-02"`
+	ReturnDate    *string `json:"returnDate" validate:"omitempty,datetime=2006-01-02"`
+	Passengers    int     `json:"passengers" validate:"required,min=1,max=9"` // MaxPassengers
+	CabinClass    string  `json:"cabinClass" validate:"required,cabin_class"`
+	PromoCode     string  `json:"promoCode"`
 }
 
 type FilterOptions struct {
-	MinPrice      *float64 `json:"minPrice"`
-	MaxPrice      *float64 `json:"maxPrice"`
-	MaxStops      *int     `json:"maxStops"`
-	Airlines      []string `json:"airlines"`
-	MinDuration   *int     `json:"minDuration"`
-	MaxDuration   *int     `json:"maxDuration"`
-	SortBy        string   `json:"sortBy"` // price_asc, price_desc, duration_asc, duration_desc, departure_time
+	MinPrice      *float64 `json:"minPrice" query:"minPrice" validate:"omitempty,gte=0"`
+	MaxPrice      *float64 `json:"maxPrice" query:"maxPrice" validate:"omitempty,gte=0"`
+	MaxStops      *int     `json:"maxStops" query:"maxStops" validate:"omitempty,gte=0"`
+	Airlines      []string `json:"airlines" query:"airlines"`
+	MinDuration   *int     `json:"minDuration" query:"minDuration" validate:"omitempty,gte=0"`
+	MaxDuration   *int     `json:"maxDuration" query:"maxDuration" validate:"omitempty,gte=0"`
+	SortBy        string   `json:"sortBy" query:"sortBy" validate:"omitempty,sort_option"` // one of SortOptions
+	SortKeys      []string `json:"sortKeys" query:"sortKeys" validate:"omitempty,max=5,dive,sort_option"` // MaxSortKeys, applied in order and overriding SortBy
+	InPolicyOnly  bool     `json:"inPolicyOnly" query:"inPolicyOnly"` // hide flights breaking the tenant's travel policy
+
+	// Time of day, in the local time of the origin for departures and of the destination
+	// for arrivals. A window and buckets both have to match when given together.
+	DepartureWindow  string   `json:"departureWindow" query:"departureWindow" validate:"omitempty,time_window"` // HH:MM-HH:MM, wraps past midnight when the end is earlier
+	ArrivalWindow    string   `json:"arrivalWindow" query:"arrivalWindow" validate:"omitempty,time_window"`
+	DepartureBuckets []string `json:"departureBuckets" query:"departureBuckets" validate:"omitempty,dive,time_bucket"` // any of DepartureBuckets
+	ArrivalBuckets   []string `json:"arrivalBuckets" query:"arrivalBuckets" validate:"omitempty,dive,time_bucket"`
+	ArriveBy         string   `json:"arriveBy" query:"arriveBy" validate:"omitempty,datetime=15:04"` // latest arrival, on the day of departure
+
+	// Aircraft, amenities, baggage and fare flexibility
+	AircraftBody           string   `json:"aircraftBody" query:"aircraftBody" validate:"omitempty,oneof=narrow_body wide_body"`
+	AircraftTypes          []string `json:"aircraftTypes" query:"aircraftTypes"` // any of them, found in the aircraft name, e.g. A330
+	Amenities              []string `json:"amenities" query:"amenities" validate:"omitempty,dive,amenity"` // every one of them
+	CheckedBaggageIncluded bool     `json:"checkedBaggageIncluded" query:"checkedBaggageIncluded"`
+	MinCheckedBaggageKg    *int     `json:"minCheckedBaggageKg" query:"minCheckedBaggageKg" validate:"omitempty,gte=0"` // pieces count as StandardBagKg
+	Refundable             bool     `json:"refundable" query:"refundable"` // flights with unknown fare rules are excluded
+	Changeable             bool     `json:"changeable" query:"changeable"`
+	Debug         bool     `json:"-"`            // explain ranking and filtering, set for admin callers only
+
+	// Pagination, a cursor reads another page of an earlier search instead of searching again
+	Limit  int    `json:"limit" query:"limit" validate:"omitempty,min=1,max=100"` // MaxPageSize
+	Cursor string `json:"cursor" query:"cursor"`
+
+	// Ranking overrides for best_value, the tenant's or the default settings apply when unset.
+	// The weights and preferred departure can only be sent in a search body.
+	Scorer             string          `json:"scorer" query:"scorer"`
+	RankingWeights     *RankingWeights `json:"rankingWeights"`
+	PreferredDeparture *TimeWindow     `json:"preferredDeparture"` // used by the departure_time scorer and smart results
+
+	Mode string `json:"mode" query:"mode" validate:"omitempty,oneof=all smart"` // ResultModeAll by default
+
+	// Diversification reranks the top results so no airline or departure time fills them
+	Diversify     bool `json:"diversify" query:"diversify"`
+	MaxPerAirline *int `json:"maxPerAirline" query:"maxPerAirline" validate:"omitempty,min=1"` // overrides DIVERSIFY_AIRLINE_CAP
+}
+
+// SortOrder returns the sort keys to apply in order, SortKeys when given, else
+// SortBy, else DefaultSortKey
+func (fo FilterOptions) SortOrder() []string {
+	if len(fo.SortKeys) > 0 {
+		return fo.SortKeys
+	}
+	if fo.SortBy != "" {
+		return []string{fo.SortBy}
+	}
+	return []string{DefaultSortKey}
+}
+
+// TimeWindow is a local time of day range in HH:MM. A window whose end is before
+// its start wraps past midnight.
+type TimeWindow struct {
+	From string `json:"from" validate:"required,datetime=15:04"`
+	To   string `json:"to" validate:"required,datetime=15:04"`
+}
+
+// Layover is a connection between two legs of a flight
+type Layover struct {
+	Airport string    `json:"airport"`
+	Minutes int       `json:"minutes"`
+	Start   *time.Time `json:"start,omitempty"` // nil when the provider only reports the duration
 }
 
 type Flight struct {
@@ -38,8 +101,20 @@ type Flight struct {
 	Currency      string    `json:"currency"`
 	Stops         int       `json:"stops"`
 	Aircraft      string    `json:"aircraft"`
+	AvailableSeats int      `json:"availableSeats"`
+	Amenities     []string  `json:"amenities,omitempty"` // normalized to Amenities
+	Baggage       BaggageAllowance `json:"baggage"`
+	Refundable    *bool     `json:"refundable,omitempty"` // nil when the provider does not report its fare rules
+	Changeable    *bool     `json:"changeable,omitempty"`
 	Provider      string    `json:"provider"`
 	BestValue     float64   `json:"bestValue"`
+	NetFare       float64           `json:"netFare"`     // provider fare before pricing rules
+	Adjustments   []PriceAdjustment `json:"adjustments"` // pricing rules applied to reach Price
+	OriginalPrice float64           `json:"originalPrice"` // selling price before the promo code, 0 when none applied
+	Policy        *PolicyStatus     `json:"policy,omitempty"` // set when the tenant has a travel policy
+	Layovers      []Layover         `json:"layovers,omitempty"` // only when the provider reports connections
+	ScoreBreakdown *ScoreBreakdown  `json:"scoreBreakdown,omitempty"` // how BestValue was reached, debug searches only
+	SmartLabels   []string          `json:"smartLabels,omitempty"` // why the flight is in smart results
 }
 
 type SearchCriteria struct {
@@ -51,12 +126,38 @@ type SearchCriteria struct {
 }
 
 type Metadata struct {
-	TotalResults       int  `json:"total_results"`
-	ProvidersQueried   int  `json:"providers_queried"`
-	ProvidersSucceeded int  `json:"providers_succeeded"`
-	ProvidersFailed    int  `json:"providers_failed"`
-	SearchTimeMs       int  `json:"search_time_ms"`
-	CacheHit          bool `json:"cache_hit"`
+	TotalResults       int              `json:"total_results"`
+	ProvidersQueried   int              `json:"providers_queried"`
+	ProvidersSucceeded int              `json:"providers_succeeded"`
+	ProvidersFailed    int              `json:"providers_failed"`
+	ProvidersThrottled int              `json:"providers_throttled"`
+	SearchTimeMs       int              `json:"search_time_ms"`
+	CacheHit           bool             `json:"cache_hit"`
+	Providers          []ProviderStatus `json:"providers"`
+	Promo              *PromoSummary    `json:"promo,omitempty"`
+	SearchID           string           `json:"search_id,omitempty"`   // re-read through GET /api/searches/{id}
+	ExpiresAt          *time.Time       `json:"expires_at,omitempty"`  // when the search is no longer stored
+	NextCursor         string           `json:"next_cursor,omitempty"` // empty on the last page
+	PrevCursor         string           `json:"prev_cursor,omitempty"` // empty on the first page
+}
+
+// Provider outcome statuses
+const (
+	ProviderStatusSuccess   = "success"
+	ProviderStatusFailed    = "failed"
+	ProviderStatusTimeout   = "timeout"
+	ProviderStatusThrottled = "throttled"
+)
+
+// ProviderStatus is the outcome of querying a single provider
+type ProviderStatus struct {
+	Name        string `json:"name"`
+	Status      string `json:"status"`
+	LatencyMs   int    `json:"latency_ms"`
+	Attempts    int    `json:"attempts"`
+	ErrorCode   string `json:"error_code,omitempty"`
+	FlightCount int    `json:"flight_count"`
+	Cached      bool   `json:"cached"`
 }
 
 type Airline struct {
@@ -76,9 +177,13 @@ type Duration struct {
 	Formatted    string `json:"formatted"`
 }
 
+// Price is the selling price. NetFare and Adjustments explain how it was reached.
 type Price struct {
-	Amount   float64 `json:"amount"`
-	Currency string  `json:"currency"`
+	Amount         float64           `json:"amount"`
+	Currency       string            `json:"currency"`
+	NetFare        float64           `json:"net_fare"`
+	OriginalAmount *float64          `json:"original_amount,omitempty"` // price before the promo code
+	Adjustments    []PriceAdjustment `json:"adjustments,omitempty"`
 }
 
 type Baggage struct {
@@ -101,18 +206,28 @@ type ExpectedFlight struct {
 	Aircraft       *string   `json:"aircraft"`
 	Amenities      []string  `json:"amenities"`
 	Baggage        Baggage   `json:"baggage"`
+	Refundable     *bool     `json:"refundable,omitempty"`
+	Changeable     *bool     `json:"changeable,omitempty"`
+	Policy         *PolicyStatus `json:"policy,omitempty"`
+	Layovers       []Layover     `json:"layovers,omitempty"`
+	ScoreBreakdown *ScoreBreakdown `json:"score_breakdown,omitempty"`
+	SmartLabels    []string        `json:"smart_labels,omitempty"`
 }
 
 type ExpectedSearchResponse struct {
 	SearchCriteria SearchCriteria   `json:"search_criteria"`
 	Metadata       Metadata         `json:"metadata"`
 	Flights        []ExpectedFlight `json:"flights"`
+	Facets         *Facets          `json:"facets,omitempty"` // of every flight found, before the request's filters
+	Debug          *SearchDebug     `json:"debug,omitempty"`
 }
 
 type ErrorResponse struct {
-	Status  string `json:"status"`
-	Code    string `json:"code"`
-	Message string `json:"message"`
+	Status  string                `json:"status"`
+	Code    string                `json:"code"`
+	Message string                `json:"message"`
+	ResetAt *time.Time            `json:"reset_at,omitempty"`
+	Errors  []apperror.FieldError `json:"errors,omitempty"` // invalid fields of a VALIDATION_ERROR
 }
 
 type PriceRange struct {
@@ -134,10 +249,5 @@ type FiltersResponse struct {
 	PriceRange    PriceRange     `json:"priceRange"`
 	DurationRange DurationRange  `json:"durationRange"`
 	MaxStops      int            `json:"maxStops"`
+	Facets        *Facets        `json:"facets,omitempty"` // set for a stored search
 }
-
-// Validate validates the SearchRequest
-func (sr *SearchRequest) Validate() error {
-	validate := validator.New()
-	return validate.Struct(sr)
-}
\ No newline at end of file
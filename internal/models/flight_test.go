@@ -1,10 +1,18 @@
 package models
 
 import (
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestSearchRequest_Validate(t *testing.T) {
+	now := time.Date(2025, 12, 1, 9, 0, 0, 0, time.UTC)
+	returnDate := "2025-12-20"
+	earlyReturn := "2025-12-10"
+
 	tests := []struct {
 		name    string
 		req     SearchRequest
@@ -52,14 +60,226 @@ func TestSearchRequest_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name:    "round trip",
+			req:     SearchRequest{Origin: "CGK", Destination: "DPS", DepartureDate: "2025-12-15", ReturnDate: &returnDate, Passengers: 2, CabinClass: "business"},
+			wantErr: false,
+		},
+		{
+			name:    "lowercase airport",
+			req:     SearchRequest{Origin: "cgk", Destination: "DPS", DepartureDate: "2025-12-15", Passengers: 1, CabinClass: "economy"},
+			wantErr: true,
+		},
+		{
+			name:    "same origin and destination",
+			req:     SearchRequest{Origin: "CGK", Destination: "CGK", DepartureDate: "2025-12-15", Passengers: 1, CabinClass: "economy"},
+			wantErr: true,
+		},
+		{
+			name:    "bad date format",
+			req:     SearchRequest{Origin: "CGK", Destination: "DPS", DepartureDate: "15-12-2025", Passengers: 1, CabinClass: "economy"},
+			wantErr: true,
+		},
+		{
+			name:    "departure in the past",
+			req:     SearchRequest{Origin: "CGK", Destination: "DPS", DepartureDate: "2025-11-29", Passengers: 1, CabinClass: "economy"},
+			wantErr: true,
+		},
+		{
+			name:    "departure yesterday in UTC is still today somewhere",
+			req:     SearchRequest{Origin: "CGK", Destination: "DPS", DepartureDate: "2025-11-30", Passengers: 1, CabinClass: "economy"},
+			wantErr: false,
+		},
+		{
+			name:    "return before departure",
+			req:     SearchRequest{Origin: "CGK", Destination: "DPS", DepartureDate: "2025-12-15", ReturnDate: &earlyReturn, Passengers: 1, CabinClass: "economy"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown cabin",
+			req:     SearchRequest{Origin: "CGK", Destination: "DPS", DepartureDate: "2025-12-15", Passengers: 1, CabinClass: "premium"},
+			wantErr: true,
+		},
+		{
+			name:    "too many passengers",
+			req:     SearchRequest{Origin: "CGK", Destination: "DPS", DepartureDate: "2025-12-15", Passengers: MaxPassengers + 1, CabinClass: "economy"},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := tt.req.Validate()
+			err := tt.req.ValidateAt(now)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("SearchRequest.Validate() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestSearchRequest_FieldErrors(t *testing.T) {
+	now := time.Date(2025, 12, 1, 9, 0, 0, 0, time.UTC)
+	req := SearchRequest{Origin: "CGK", Destination: "CGK", DepartureDate: "2025-13-01", Passengers: 0, CabinClass: "economy"}
+
+	fields := req.FieldErrors(now)
+	got := make(map[string]string)
+	for _, f := range fields {
+		got[f.Field] = f.Rule
+	}
+	want := map[string]string{"destination": "nefield", "departureDate": "datetime", "passengers": "required"}
+	if len(got) != len(want) {
+		t.Fatalf("Expected fields %v, got %+v", want, fields)
+	}
+	for field, rule := range want {
+		if got[field] != rule {
+			t.Errorf("Expected %s to fail %s, got %q", field, rule, got[field])
+		}
+	}
+}
+
+func TestFilterOptions_Validate(t *testing.T) {
+	price := func(v float64) *float64 { return &v }
+	minutes := func(v int) *int { return &v }
+
+	tests := []struct {
+		name    string
+		filters FilterOptions
+		wantErr bool
+	}{
+		{"no filters", FilterOptions{}, false},
+		{"valid ranges", FilterOptions{MinPrice: price(500000), MaxPrice: price(1500000), MaxStops: minutes(0), SortBy: "price_asc"}, false},
+		{"min price above max", FilterOptions{MinPrice: price(2000000), MaxPrice: price(1000000)}, true},
+		{"negative price", FilterOptions{MinPrice: price(-1)}, true},
+		{"negative stops", FilterOptions{MaxStops: minutes(-1)}, true},
+		{"min duration above max", FilterOptions{MinDuration: minutes(200), MaxDuration: minutes(100)}, true},
+		{"unknown sort", FilterOptions{SortBy: "cheapest"}, true},
+		{"sort keys", FilterOptions{SortKeys: []string{"stops_asc", "price_asc", "departure_time"}}, false},
+		{"unknown sort key", FilterOptions{SortKeys: []string{"stops_asc", "cheapest"}}, true},
+		{"too many sort keys", FilterOptions{SortKeys: []string{"stops_asc", "price_asc", "duration_asc", "departure_time", "airline_asc", "seats_desc"}}, true},
+		{"ranking overrides", FilterOptions{RankingWeights: &RankingWeights{Price: 1}, PreferredDeparture: &TimeWindow{From: "06:00", To: "10:00"}}, false},
+		{"negative ranking weight", FilterOptions{RankingWeights: &RankingWeights{Price: 1, Stops: -1}}, true},
+		{"no positive ranking weight", FilterOptions{RankingWeights: &RankingWeights{OvernightLayover: 1}}, true},
+		{"invalid departure window", FilterOptions{PreferredDeparture: &TimeWindow{From: "6am", To: "10:00"}}, true},
+		{"time of day filters", FilterOptions{DepartureWindow: "22:00-06:00", ArrivalBuckets: []string{BucketMorning}, ArriveBy: "12:00"}, false},
+		{"time window without an end", FilterOptions{DepartureWindow: "06:00"}, true},
+		{"unknown time bucket", FilterOptions{DepartureBuckets: []string{"lunch"}}, true},
+		{"invalid arrive by", FilterOptions{ArriveBy: "noon"}, true},
+		{"fare filters", FilterOptions{AircraftBody: AircraftWideBody, Amenities: []string{AmenityWifi, AmenityPower}, CheckedBaggageIncluded: true}, false},
+		{"unknown aircraft body", FilterOptions{AircraftBody: "jumbo"}, true},
+		{"smart mode", FilterOptions{Mode: ResultModeSmart}, false},
+		{"unknown mode", FilterOptions{Mode: "best"}, true},
+		{"diversify with an airline cap", FilterOptions{Diversify: true, MaxPerAirline: minutes(2)}, false},
+		{"zero airline cap", FilterOptions{Diversify: true, MaxPerAirline: minutes(0)}, true},
+		{"unknown amenity", FilterOptions{Amenities: []string{"lounge"}}, true},
+		{"negative checked baggage", FilterOptions{MinCheckedBaggageKg: minutes(-1)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.filters.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("FilterOptions.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTimeWindow_Distance(t *testing.T) {
+	at := func(hour, minute int) time.Time { return time.Date(2026, 12, 15, hour, minute, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name     string
+		window   TimeWindow
+		t        time.Time
+		expected int
+	}{
+		{"inside", TimeWindow{From: "06:00", To: "10:00"}, at(8, 0), 0},
+		{"on the bound", TimeWindow{From: "06:00", To: "10:00"}, at(10, 0), 0},
+		{"before", TimeWindow{From: "06:00", To: "10:00"}, at(5, 15), 45},
+		{"after", TimeWindow{From: "06:00", To: "10:00"}, at(12, 0), 120},
+		{"inside past midnight", TimeWindow{From: "22:00", To: "02:00"}, at(1, 0), 0},
+		{"outside past midnight", TimeWindow{From: "22:00", To: "02:00"}, at(3, 0), 60},
+		{"closest across midnight", TimeWindow{From: "01:00", To: "03:00"}, at(23, 30), 90},
+		{"invalid window", TimeWindow{From: "soon", To: "10:00"}, at(20, 0), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.window.Distance(tt.t); got != tt.expected {
+				t.Errorf("Distance() = %d, expected %d", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFilterOptions_SortOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		filters  FilterOptions
+		expected []string
+	}{
+		{"default", FilterOptions{}, []string{DefaultSortKey}},
+		{"sort by", FilterOptions{SortBy: "price_asc"}, []string{"price_asc"}},
+		{"sort keys win", FilterOptions{SortBy: "price_asc", SortKeys: []string{"stops_asc", "price_desc"}}, []string{"stops_asc", "price_desc"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.filters.SortOrder()
+			if strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("SortOrder() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAircraftBody(t *testing.T) {
+	tests := map[string]string{
+		"Airbus A330-300":  AircraftWideBody,
+		"Boeing 787-9":     AircraftWideBody,
+		"Boeing 737-900ER": AircraftNarrowBody,
+		"Airbus A320":      AircraftNarrowBody,
+		"ATR 72-600":       "",
+		"":                 "",
+	}
+	for aircraft, expected := range tests {
+		if got := AircraftBody(aircraft); got != expected {
+			t.Errorf("AircraftBody(%q) = %q, expected %q", aircraft, got, expected)
+		}
+	}
+}
+
+func TestBaggageAllowance_CheckedAllowanceKg(t *testing.T) {
+	if kg := (BaggageAllowance{CheckedKg: 20}).CheckedAllowanceKg(); kg != 20 {
+		t.Errorf("Expected 20 kg, got %d", kg)
+	}
+	if kg := (BaggageAllowance{CheckedPieces: 2}).CheckedAllowanceKg(); kg != 2*StandardBagKg {
+		t.Errorf("Expected pieces counted as %d kg each, got %d", StandardBagKg, kg)
+	}
+	if (BaggageAllowance{CabinKg: 7}).IncludesChecked() {
+		t.Error("Expected a cabin allowance not to include checked baggage")
+	}
+}
+
+// Validation tags cannot reference constants, so their limits are checked here
+func TestValidationTags_MatchLimits(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		field string
+		limit int
+	}{
+		{SearchRequest{}, "Passengers", MaxPassengers},
+		{FilterOptions{}, "Limit", MaxPageSize},
+	}
+
+	for _, tt := range tests {
+		field, ok := reflect.TypeOf(tt.value).FieldByName(tt.field)
+		if !ok {
+			t.Fatalf("Expected field %s", tt.field)
+		}
+		rule := fmt.Sprintf("max=%d", tt.limit)
+		if tag := field.Tag.Get("validate"); !strings.Contains(","+tag+",", ","+rule+",") {
+			t.Errorf("Expected %s to validate %s, got %q", tt.field, rule, tag)
+		}
+	}
+}
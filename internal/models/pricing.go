@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// Pricing rule kinds
+const (
+	PricingKindMarkup   = "markup"
+	PricingKindDiscount = "discount"
+	PricingKindFee      = "fee"
+)
+
+// Pricing rule modes
+const (
+	PricingModeFixed      = "fixed"
+	PricingModePercentage = "percentage"
+)
+
+// Sales channels
+const (
+	ChannelB2C = "b2c"
+	ChannelB2B = "b2b"
+)
+
+// PricingRule adjusts the selling price of matching flights
+type PricingRule struct {
+	ID          string    `json:"id"`
+	Description string    `json:"description"`
+	Kind        string    `json:"kind"`      // markup, discount or fee
+	Mode        string    `json:"mode"`      // fixed or percentage
+	Value       float64   `json:"value"`     // amount in the fare currency, or percent of the net fare
+	Priority    int       `json:"priority"`  // higher priorities apply first
+	Stackable   bool      `json:"stackable"` // see usecase.PricingEngine for the stacking semantics
+	Match       RuleMatch `json:"match"`
+}
+
+// RuleMatch selects the flights a rule applies to. Empty fields match everything.
+type RuleMatch struct {
+	Tenants         []string   `json:"tenants"`
+	Channels        []string   `json:"channels"`
+	Airlines        []string   `json:"airlines"`
+	Routes          []string   `json:"routes"` // "CGK-DPS"
+	CabinClasses    []string   `json:"cabinClasses"`
+	DepartureAfter  *time.Time `json:"departureAfter"`
+	DepartureBefore *time.Time `json:"departureBefore"`
+}
+
+// PriceAdjustment is one rule applied to a fare. Discounts have negative amounts.
+type PriceAdjustment struct {
+	RuleID      string  `json:"rule_id"`
+	Kind        string  `json:"kind"`
+	Description string  `json:"description,omitempty"`
+	Amount      float64 `json:"amount"`
+}
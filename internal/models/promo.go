@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// AdjustmentKindPromo marks the price adjustment of a promo code
+const AdjustmentKindPromo = "promo"
+
+// PromoCode is a discount a client applies by sending its code with a search
+type PromoCode struct {
+	Code        string     `json:"code"` // stored upper case
+	Description string     `json:"description"`
+	Mode        string     `json:"mode"`        // fixed or percentage
+	Value       float64    `json:"value"`       // amount off, or percent off the selling price
+	MaxDiscount float64    `json:"maxDiscount"` // caps percentage discounts, 0 means no cap
+	StartsAt    *time.Time `json:"startsAt"`
+	ExpiresAt   *time.Time `json:"expiresAt"`
+	UsageLimit  int        `json:"usageLimit"` // searches the code can be applied to, 0 means unlimited
+	UsageCount  int        `json:"usageCount"` // maintained by the store, ignored on save
+	Airlines    []string   `json:"airlines"`   // empty means every airline
+	Routes      []string   `json:"routes"`     // "CGK-DPS", empty means every route
+	MinFare     float64    `json:"minFare"`    // minimum selling price before the discount
+}
+
+// PromoSummary reports how a promo code was applied to a search
+type PromoSummary struct {
+	Code              string `json:"code"`
+	DiscountedFlights int    `json:"discounted_flights"`
+}
@@ -0,0 +1,65 @@
+package models
+
+// ScoreBreakdown explains a best value score as the sum of its weighted components
+type ScoreBreakdown struct {
+	Total      float64          `json:"total"`
+	Components []ScoreComponent `json:"components"`
+}
+
+// ScoreComponent is one criterion of a score. Value is the flight's normalized
+// standing on it, a penalty is negative, and Contribution is Value times Weight.
+type ScoreComponent struct {
+	Name         string  `json:"name"`
+	Value        float64 `json:"value"`
+	Weight       float64 `json:"weight"`
+	Contribution float64 `json:"contribution"`
+}
+
+// Add records a component and adds its contribution to the total
+func (b *ScoreBreakdown) Add(name string, value, weight float64) {
+	contribution := value * weight
+	b.Components = append(b.Components, ScoreComponent{
+		Name:         name,
+		Value:        value,
+		Weight:       weight,
+		Contribution: contribution,
+	})
+	b.Total += contribution
+}
+
+// SearchDebug explains how a search ranked and filtered its flights. It is only
+// returned to admin callers.
+type SearchDebug struct {
+	Scorer           string           `json:"scorer"`
+	Weights          RankingWeights   `json:"weights"`
+	Excluded         []ExcludedFlight `json:"excluded"`
+	ExcludedByFilter map[string]int   `json:"excluded_by_filter"`
+}
+
+// ExcludedFlight is a flight a filter removed from the results
+type ExcludedFlight struct {
+	ID       string  `json:"id"`
+	Provider string  `json:"provider"`
+	Airline  string  `json:"airline"`
+	Price    float64 `json:"price"`
+	Filter   string  `json:"filter"` // the first filter the flight failed
+}
+
+// Result modes, smart keeps only the flights no other flight beats on every criterion
+const (
+	ResultModeAll   = "all"
+	ResultModeSmart = "smart"
+)
+
+// Labels explaining why a flight is in smart results
+const (
+	SmartLabelCheapest      = "cheapest"
+	SmartLabelFastest       = "fastest"
+	SmartLabelBestNonstop   = "best_nonstop" // the cheapest direct flight
+	SmartLabelBestDeparture = "best_departure"
+	SmartLabelBalanced      = "balanced" // best on no single criterion, but no flight beats it on all
+)
+
+// ConvenientDeparture is the departure window smart results prefer when the
+// request gives no preferredDeparture
+var ConvenientDeparture = TimeWindow{From: "07:00", To: "21:00"}
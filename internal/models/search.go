@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// MaxPageSize is the largest page of flights a search returns
+const MaxPageSize = 100
+
+// SearchSession is a search's normalized results: priced, annotated and matched to
+// the route, but not yet filtered or ranked. It is stored so the results can be
+// filtered, sorted and paged again without querying the providers.
+type SearchSession struct {
+	ID        string           `json:"id"`
+	TenantID  string           `json:"tenant_id,omitempty"`
+	Request   SearchRequest    `json:"request"`
+	CreatedAt time.Time        `json:"created_at"`
+	ExpiresAt time.Time        `json:"expires_at"`
+	Providers []ProviderStatus `json:"providers"`
+	Promo     *PromoSummary    `json:"promo,omitempty"`
+	Flights   []Flight         `json:"flights"`
+}
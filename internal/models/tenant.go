@@ -0,0 +1,84 @@
+package models
+
+// Tenant is a customer with its own provider set and search settings. API keys
+// belong to a tenant; requests without a tenant use the service defaults.
+type Tenant struct {
+	ID                  string                       `json:"id"`
+	Name                string                       `json:"name"`
+	EnabledProviders    []string                     `json:"enabledProviders"`              // provider names, empty means every provider
+	ProviderCredentials map[string]map[string]string `json:"providerCredentials,omitempty"` // provider name -> credential fields
+	DefaultCurrency     string                       `json:"defaultCurrency"`
+	RankingWeights      *RankingWeights              `json:"rankingWeights,omitempty"` // nil uses the default weights
+	Scorer              string                       `json:"scorer,omitempty"`         // best value scorer, empty uses the default
+	MaxResults          int                          `json:"maxResults"`               // 0 means no limit
+	Channel             string                       `json:"channel"`                  // b2c or b2b, selects pricing rules
+	TravelPolicy        *TravelPolicy                `json:"travelPolicy,omitempty"`   // corporate tenants only
+}
+
+// RankingWeights weigh the components of the best value score. DepartureTime and
+// OvernightLayover are only used by the scorers that rank on them.
+type RankingWeights struct {
+	Price            float64 `json:"price" validate:"gte=0"`
+	Stops            float64 `json:"stops" validate:"gte=0"`
+	Duration         float64 `json:"duration" validate:"gte=0"`
+	DepartureTime    float64 `json:"departureTime" validate:"gte=0"`
+	OvernightLayover float64 `json:"overnightLayover" validate:"gte=0"` // penalty
+}
+
+// DefaultRankingWeights are used for requests without tenant weights
+var DefaultRankingWeights = RankingWeights{Price: 0.5, Stops: 0.3, Duration: 0.2, DepartureTime: 0.2, OvernightLayover: 0.3}
+
+// HasPositive reports whether any component of the score is weighted. The
+// overnight penalty alone cannot rank flights.
+func (w RankingWeights) HasPositive() bool {
+	return w.Price > 0 || w.Stops > 0 || w.Duration > 0 || w.DepartureTime > 0
+}
+
+// EnablesProvider reports whether the tenant searches a provider
+func (t *Tenant) EnablesProvider(name string) bool {
+	if t == nil || len(t.EnabledProviders) == 0 {
+		return true
+	}
+	for _, provider := range t.EnabledProviders {
+		if provider == name {
+			return true
+		}
+	}
+	return false
+}
+
+// SalesChannel returns the tenant's channel, b2c outside a tenant
+func (t *Tenant) SalesChannel() string {
+	if t == nil || t.Channel == "" {
+		return ChannelB2C
+	}
+	return t.Channel
+}
+
+// Weights returns the tenant's ranking weights or the defaults
+func (t *Tenant) Weights() RankingWeights {
+	if t == nil || t.RankingWeights == nil {
+		return DefaultRankingWeights
+	}
+	return *t.RankingWeights
+}
+
+// CredentialMask replaces credential values returned from the API
+const CredentialMask = "********"
+
+// Redacted returns a copy of the tenant safe to return from the API, with
+// credential values masked
+func (t Tenant) Redacted() Tenant {
+	if len(t.ProviderCredentials) == 0 {
+		return t
+	}
+	masked := make(map[string]map[string]string, len(t.ProviderCredentials))
+	for provider, fields := range t.ProviderCredentials {
+		masked[provider] = make(map[string]string, len(fields))
+		for field := range fields {
+			masked[provider][field] = CredentialMask
+		}
+	}
+	t.ProviderCredentials = masked
+	return t
+}
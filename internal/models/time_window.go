@@ -0,0 +1,68 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+const minutesPerDay = 24 * 60
+
+// ParseTimeWindow parses a window written "HH:MM-HH:MM", ok is false when s is
+// not in that format
+func ParseTimeWindow(s string) (window TimeWindow, ok bool) {
+	from, to, found := strings.Cut(s, "-")
+	if !found {
+		return TimeWindow{}, false
+	}
+	window = TimeWindow{From: strings.TrimSpace(from), To: strings.TrimSpace(to)}
+	_, _, ok = window.bounds()
+	return window, ok
+}
+
+// bounds returns the window as minutes after midnight, ok is false when a bound
+// is not a valid HH:MM time
+func (w TimeWindow) bounds() (from, to int, ok bool) {
+	start, err := time.Parse("15:04", w.From)
+	if err != nil {
+		return 0, 0, false
+	}
+	end, err := time.Parse("15:04", w.To)
+	if err != nil {
+		return 0, 0, false
+	}
+	return start.Hour()*60 + start.Minute(), end.Hour()*60 + end.Minute(), true
+}
+
+// Contains reports whether the local time of day of t falls in the window, both
+// bounds included
+func (w TimeWindow) Contains(t time.Time) bool {
+	return w.Distance(t) == 0
+}
+
+// Distance returns how many minutes the local time of day of t is outside the
+// window, 0 when it is inside. An invalid window contains every time.
+func (w TimeWindow) Distance(t time.Time) int {
+	from, to, ok := w.bounds()
+	if !ok {
+		return 0
+	}
+	minute := t.Hour()*60 + t.Minute()
+
+	inside := from <= minute && minute <= to
+	if to < from {
+		inside = minute >= from || minute <= to
+	}
+	if inside {
+		return 0
+	}
+	return min(clockDistance(minute, from), clockDistance(minute, to))
+}
+
+// clockDistance is the shortest number of minutes between two times of day
+func clockDistance(a, b int) int {
+	d := a - b
+	if d < 0 {
+		d = -d
+	}
+	return min(d, minutesPerDay-d)
+}
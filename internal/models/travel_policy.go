@@ -0,0 +1,43 @@
+package models
+
+// Route classes used by travel policy fare caps, by flight duration
+const (
+	RouteClassShortHaul  = "short_haul"  // up to 3 hours
+	RouteClassMediumHaul = "medium_haul" // up to 6 hours
+	RouteClassLongHaul   = "long_haul"
+)
+
+// RouteClassOf classifies a flight by its duration in minutes
+func RouteClassOf(durationMinutes int) string {
+	switch {
+	case durationMinutes <= 180:
+		return RouteClassShortHaul
+	case durationMinutes <= 360:
+		return RouteClassMediumHaul
+	default:
+		return RouteClassLongHaul
+	}
+}
+
+// TravelPolicy is a corporate tenant's booking policy. Flights breaking it are
+// annotated with the reasons, or hidden when HideOutOfPolicy is set.
+type TravelPolicy struct {
+	MaxFares          map[string]float64 `json:"maxFares"`          // route class -> maximum selling price
+	CabinRules        []CabinRule        `json:"cabinRules"`        // empty allows every cabin
+	PreferredAirlines []string           `json:"preferredAirlines"` // empty allows every airline
+	MinLeadDays       int                `json:"minLeadDays"`       // days between the search and departure
+	HideOutOfPolicy   bool               `json:"hideOutOfPolicy"`
+}
+
+// CabinRule allows cabins on flights lasting at least MinDuration minutes. The rule
+// with the highest MinDuration not above the flight duration applies.
+type CabinRule struct {
+	MinDuration int      `json:"minDuration"`
+	Cabins      []string `json:"cabins"`
+}
+
+// PolicyStatus is the travel policy verdict on a flight
+type PolicyStatus struct {
+	InPolicy bool     `json:"in_policy"`
+	Reasons  []string `json:"reasons,omitempty"`
+}
@@ -0,0 +1,219 @@
+package models
+
+import (
+	"errors"
+	"flight-aggregator/internal/apperror"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// MaxPassengers is the largest party a single search can be made for
+const MaxPassengers = 9
+
+// DateFormat is the format of search dates
+const DateFormat = "2006-01-02"
+
+// CabinClasses are the cabins a search can ask for
+var CabinClasses = []string{"economy", "business", "first"}
+
+// SortOptions are the accepted values of FilterOptions.SortBy and SortKeys
+var SortOptions = []string{
+	"price_asc", "price_desc", "duration_asc", "duration_desc",
+	"departure_time", "departure_time_desc", "arrival_time", "arrival_time_desc",
+	"stops_asc", "stops_desc", "airline_asc", "airline_desc",
+	"seats_asc", "seats_desc", "best_value",
+}
+
+// DefaultSortKey orders results when the request gives no sort
+const DefaultSortKey = "best_value"
+
+// MaxSortKeys caps the keys of a single search
+const MaxSortKeys = 5
+
+var iataCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// earliestZone is the last timezone to reach a new day. A date is only in the
+// past once it has ended there, so no origin airport sees a valid date rejected.
+var earliestZone = time.FixedZone("UTC-12", -12*60*60)
+
+// validate is shared, validator caches struct metadata across calls
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	// Report fields by their JSON name
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	v.RegisterValidation("iata", func(fl validator.FieldLevel) bool {
+		return iataCodePattern.MatchString(fl.Field().String())
+	})
+	v.RegisterValidation("cabin_class", func(fl validator.FieldLevel) bool {
+		return contains(CabinClasses, fl.Field().String())
+	})
+	v.RegisterValidation("sort_option", func(fl validator.FieldLevel) bool {
+		return contains(SortOptions, fl.Field().String())
+	})
+	v.RegisterValidation("time_window", func(fl validator.FieldLevel) bool {
+		_, ok := ParseTimeWindow(fl.Field().String())
+		return ok
+	})
+	v.RegisterValidation("amenity", func(fl validator.FieldLevel) bool {
+		return contains(Amenities, fl.Field().String())
+	})
+	v.RegisterValidation("time_bucket", func(fl validator.FieldLevel) bool {
+		return isDepartureBucket(fl.Field().String())
+	})
+	return v
+}
+
+// Validate validates the SearchRequest against today's date
+func (sr *SearchRequest) Validate() error {
+	return sr.ValidateAt(time.Now())
+}
+
+// ValidateAt validates the SearchRequest, treating now as the current time
+func (sr *SearchRequest) ValidateAt(now time.Time) error {
+	if fields := sr.FieldErrors(now); len(fields) > 0 {
+		return apperror.Validation(fields)
+	}
+	return nil
+}
+
+// FieldErrors returns every invalid field of the request
+func (sr *SearchRequest) FieldErrors(now time.Time) []apperror.FieldError {
+	fields := structFieldErrors(sr)
+
+	departure, err := time.Parse(DateFormat, sr.DepartureDate)
+	if err != nil {
+		return fields
+	}
+	today := now.In(earliestZone).Format(DateFormat)
+	if sr.DepartureDate < today {
+		fields = append(fields, apperror.FieldError{Field: "departureDate", Rule: "future", Message: "must not be in the past"})
+	}
+	if sr.ReturnDate != nil {
+		if ret, err := time.Parse(DateFormat, *sr.ReturnDate); err == nil && ret.Before(departure) {
+			fields = append(fields, apperror.FieldError{Field: "returnDate", Rule: "gtefield", Message: "must not be before departureDate"})
+		}
+	}
+	return fields
+}
+
+// Validate validates the filter values and their ranges
+func (fo *FilterOptions) Validate() error {
+	if fields := fo.FieldErrors(); len(fields) > 0 {
+		return apperror.Validation(fields)
+	}
+	return nil
+}
+
+// FieldErrors returns every invalid filter
+func (fo *FilterOptions) FieldErrors() []apperror.FieldError {
+	fields := structFieldErrors(fo)
+
+	if fo.MinPrice != nil && fo.MaxPrice != nil && *fo.MinPrice > *fo.MaxPrice {
+		fields = append(fields, apperror.FieldError{Field: "minPrice", Rule: "ltefield", Message: "must not be greater than maxPrice"})
+	}
+	if fo.MinDuration != nil && fo.MaxDuration != nil && *fo.MinDuration > *fo.MaxDuration {
+		fields = append(fields, apperror.FieldError{Field: "minDuration", Rule: "ltefield", Message: "must not be greater than maxDuration"})
+	}
+	if fo.RankingWeights != nil && !fo.RankingWeights.HasPositive() {
+		fields = append(fields, apperror.FieldError{Field: "rankingWeights", Rule: "positive", Message: "needs at least one positive weight"})
+	}
+	return fields
+}
+
+// structFieldErrors runs the validate tags of s
+func structFieldErrors(s interface{}) []apperror.FieldError {
+	err := validate.Struct(s)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return []apperror.FieldError{{Field: "request", Rule: "invalid", Message: err.Error()}}
+	}
+
+	fields := make([]apperror.FieldError, len(validationErrs))
+	for i, fe := range validationErrs {
+		// Nested fields keep their path, e.g. preferredDeparture.from
+		field := fe.Namespace()
+		if idx := strings.Index(field, "."); idx != -1 {
+			field = field[idx+1:]
+		}
+		fields[i] = apperror.FieldError{
+			Field:   field,
+			Rule:    fe.Tag(),
+			Message: ruleMessage(fe),
+		}
+	}
+	return fields
+}
+
+func ruleMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "iata":
+		return "must be a 3-letter uppercase IATA airport code"
+	case "nefield":
+		return "must differ from " + lowerFirst(fe.Param())
+	case "datetime":
+		if fe.Param() == "15:04" {
+			return "must be a time in HH:MM format"
+		}
+		return "must be a date in YYYY-MM-DD format"
+	case "min", "gte":
+		if fe.Kind() == reflect.Slice {
+			return "must have at least " + fe.Param() + " items"
+		}
+		return "must be at least " + fe.Param()
+	case "max", "lte":
+		if fe.Kind() == reflect.Slice {
+			return "must have at most " + fe.Param() + " items"
+		}
+		return "must be at most " + fe.Param()
+	case "cabin_class":
+		return "must be one of " + strings.Join(CabinClasses, ", ")
+	case "sort_option":
+		return "must be one of " + strings.Join(SortOptions, ", ")
+	case "oneof":
+		return "must be one of " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "amenity":
+		return "must be one of " + strings.Join(Amenities, ", ")
+	case "time_window":
+		return "must be a time window in HH:MM-HH:MM format"
+	case "time_bucket":
+		return "must be one of " + strings.Join(departureBucketNames(), ", ")
+	default:
+		return fmt.Sprintf("failed the %s rule", fe.Tag())
+	}
+}
+
+// lowerFirst turns a Go field name given as a rule parameter into its JSON name
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
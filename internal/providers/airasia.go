@@ -3,10 +3,11 @@ package providers
 import (
 	"context"
 	"encoding/json"
+	"flight-aggregator/internal/apperror"
 	"flight-aggregator/internal/models"
 	"flight-aggregator/internal/utils"
-	"fmt"
 	"math/rand"
+	"net/http"
 	"os"
 	"time"
 )
@@ -32,6 +33,8 @@ type AirAsiaResponse struct {
 		} `json:"stops,omitempty"`
 		PriceIDR    float64 `json:"price_idr"`
 		CabinClass  string  `json:"cabin_class"`
+		Seats       int     `json:"seats"`
+		BaggageNote string  `json:"baggage_note"`
 	} `json:"flights"`
 }
 
@@ -51,15 +54,22 @@ func (a *AirAsiaProvider) GetName() string {
 
 func (a *AirAsiaProvider) GetFlights(ctx context.Context, req models.SearchRequest) ([]models.Flight, error) {
 	if a == nil {
-		return nil, fmt.Errorf("PROVIDER_ERROR: AirAsia provider not initialized")
+		return nil, apperror.New(apperror.CodeProvider, "AirAsia provider not initialized")
 	}
 	// Simulate 50-150ms delay for AirAsia
 	delay := 50 + rand.Intn(101) // 50-150ms
-	time.Sleep(time.Duration(delay) * time.Millisecond)
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(time.Duration(delay) * time.Millisecond):
+	}
 
 	// Simulate 90% success rate
 	if rand.Float64() > a.config.SuccessRate {
-		return nil, fmt.Errorf("PROVIDER_ERROR: AirAsia service temporarily unavailable")
+		return nil, apperror.Wrap(apperror.CodeProvider, &utils.StatusError{
+			StatusCode: http.StatusServiceUnavailable,
+			Message:    "upstream returned 503",
+		}, "AirAsia service temporarily unavailable")
 	}
 
 	// Try different paths for mock data file
@@ -78,12 +88,12 @@ func (a *AirAsiaProvider) GetFlights(ctx context.Context, req models.SearchReque
 		}
 	}
 	if err != nil {
-		return nil, fmt.Errorf("PROVIDER_ERROR: AirAsia service unavailable")
+		return nil, apperror.New(apperror.CodeProvider, "AirAsia service unavailable")
 	}
 
 	var response AirAsiaResponse
 	if err := json.Unmarshal(data, &response); err != nil {
-		return nil, fmt.Errorf("PROVIDER_ERROR: Invalid response from AirAsia")
+		return nil, utils.Permanent(apperror.Wrap(apperror.CodeProvider, err, "Invalid response from AirAsia"))
 	}
 
 	var flights []models.Flight
@@ -107,19 +117,21 @@ func (a *AirAsiaProvider) GetFlights(ctx context.Context, req models.SearchReque
 		}
 
 		flight := models.Flight{
-			ID:            f.FlightCode,
-			Airline:       f.Airline,
-			FlightNumber:  f.FlightCode,
-			Origin:        f.FromAirport,
-			Destination:   f.ToAirport,
-			DepartureTime: depTime,
-			ArrivalTime:   arrTime,
-			Duration:      duration,
-			Price:         f.PriceIDR,
-			Currency:      "IDR",
-			Stops:         stops,
-			Aircraft:      "Airbus A320", // Default aircraft for AirAsia
-			Provider:      a.GetName(),
+			ID:             f.FlightCode,
+			Airline:        f.Airline,
+			FlightNumber:   f.FlightCode,
+			Origin:         f.FromAirport,
+			Destination:    f.ToAirport,
+			DepartureTime:  depTime,
+			ArrivalTime:    arrTime,
+			Duration:       duration,
+			Price:          f.PriceIDR,
+			Currency:       "IDR",
+			Stops:          stops,
+			Aircraft:       "Airbus A320", // Default aircraft for AirAsia
+			AvailableSeats: f.Seats,
+			Baggage:        parseBaggageInfo(f.BaggageNote),
+			Provider:       a.GetName(),
 		}
 		flights = append(flights, flight)
 	}
@@ -0,0 +1,60 @@
+package providers
+
+import (
+	"flight-aggregator/internal/models"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// amenityNames maps the providers' amenity names to models amenities
+var amenityNames = map[string]string{
+	"wifi":          models.AmenityWifi,
+	"meal":          models.AmenityMeal,
+	"power_outlet":  models.AmenityPower,
+	"power":         models.AmenityPower,
+	"entertainment": models.AmenityEntertainment,
+	"snack":         models.AmenitySnack,
+	"beverage":      models.AmenityBeverage,
+}
+
+// normalizeAmenities returns the known amenities among names, without duplicates
+func normalizeAmenities(names []string) []string {
+	var amenities []string
+	seen := make(map[string]bool)
+	for _, name := range names {
+		amenity, ok := amenityNames[strings.ToLower(strings.TrimSpace(name))]
+		if !ok || seen[amenity] {
+			continue
+		}
+		seen[amenity] = true
+		amenities = append(amenities, amenity)
+	}
+	return amenities
+}
+
+var kgPattern = regexp.MustCompile(`(\d+)\s*kg`)
+
+// parseKg reads a weight such as "20 kg", 0 when there is none
+func parseKg(s string) int {
+	matches := kgPattern.FindStringSubmatch(strings.ToLower(s))
+	if len(matches) != 2 {
+		return 0
+	}
+	kg, _ := strconv.Atoi(matches[1])
+	return kg
+}
+
+// parseBaggageInfo reads a free text allowance such as "7kg cabin, 20kg checked"
+func parseBaggageInfo(info string) models.BaggageAllowance {
+	var baggage models.BaggageAllowance
+	for _, part := range strings.Split(strings.ToLower(info), ",") {
+		switch {
+		case strings.Contains(part, "cabin"):
+			baggage.CabinKg = parseKg(part)
+		case strings.Contains(part, "checked"):
+			baggage.CheckedKg = parseKg(part)
+		}
+	}
+	return baggage
+}
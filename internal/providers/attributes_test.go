@@ -0,0 +1,36 @@
+package providers
+
+import (
+	"flight-aggregator/internal/models"
+	"testing"
+)
+
+func TestNormalizeAmenities(t *testing.T) {
+	amenities := normalizeAmenities([]string{"Meal", "power_outlet", "Lounge", "meal", "wifi"})
+	expected := []string{models.AmenityMeal, models.AmenityPower, models.AmenityWifi}
+	if len(amenities) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, amenities)
+	}
+	for i := range expected {
+		if amenities[i] != expected[i] {
+			t.Errorf("Expected %v, got %v", expected, amenities)
+		}
+	}
+}
+
+func TestParseBaggageInfo(t *testing.T) {
+	tests := []struct {
+		info     string
+		expected models.BaggageAllowance
+	}{
+		{"7kg cabin, 20kg checked", models.BaggageAllowance{CabinKg: 7, CheckedKg: 20}},
+		{"Cabin baggage only, checked bags additional fee", models.BaggageAllowance{}},
+		{"", models.BaggageAllowance{}},
+	}
+
+	for _, tt := range tests {
+		if got := parseBaggageInfo(tt.info); got != tt.expected {
+			t.Errorf("parseBaggageInfo(%q) = %+v, expected %+v", tt.info, got, tt.expected)
+		}
+	}
+}
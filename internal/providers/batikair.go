@@ -3,9 +3,9 @@ package providers
 import (
 	"context"
 	"encoding/json"
+	"flight-aggregator/internal/apperror"
 	"flight-aggregator/internal/models"
 	"flight-aggregator/internal/utils"
-	"fmt"
 	"math/rand"
 	"os"
 	"regexp"
@@ -35,7 +35,10 @@ type BatikAirResponse struct {
 			TotalPrice   float64 `json:"totalPrice"`
 			CurrencyCode string  `json:"currencyCode"`
 		} `json:"fare"`
-		AircraftModel string `json:"aircraftModel"`
+		SeatsAvailable  int      `json:"seatsAvailable"`
+		AircraftModel   string   `json:"aircraftModel"`
+		BaggageInfo     string   `json:"baggageInfo"`
+		OnboardServices []string `json:"onboardServices"`
 	} `json:"results"`
 }
 
@@ -55,11 +58,15 @@ func (b *BatikAirProvider) GetName() string {
 
 func (b *BatikAirProvider) GetFlights(ctx context.Context, req models.SearchRequest) ([]models.Flight, error) {
 	if b == nil {
-		return nil, fmt.Errorf("PROVIDER_ERROR: Batik Air provider not initialized")
+		return nil, apperror.New(apperror.CodeProvider, "Batik Air provider not initialized")
 	}
 	// Simulate 200-400ms delay for Batik Air
 	delay := 200 + rand.Intn(201) // 200-400ms
-	time.Sleep(time.Duration(delay) * time.Millisecond)
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(time.Duration(delay) * time.Millisecond):
+	}
 
 	// Try different paths for mock data file
 	paths := []string{
@@ -77,12 +84,12 @@ func (b *BatikAirProvider) GetFlights(ctx context.Context, req models.SearchRequ
 		}
 	}
 	if err != nil {
-		return nil, fmt.Errorf("PROVIDER_ERROR: Batik Air service unavailable")
+		return nil, apperror.New(apperror.CodeProvider, "Batik Air service unavailable")
 	}
 
 	var response BatikAirResponse
 	if err := json.Unmarshal(data, &response); err != nil {
-		return nil, fmt.Errorf("PROVIDER_ERROR: Invalid response from Batik Air")
+		return nil, utils.Permanent(apperror.Wrap(apperror.CodeProvider, err, "Invalid response from Batik Air"))
 	}
 
 	var flights []models.Flight
@@ -98,19 +105,22 @@ func (b *BatikAirProvider) GetFlights(ctx context.Context, req models.SearchRequ
 		duration := parseDuration(f.TravelTime)
 
 		flight := models.Flight{
-			ID:            f.FlightNumber,
-			Airline:       f.AirlineName,
-			FlightNumber:  f.FlightNumber,
-			Origin:        f.Origin,
-			Destination:   f.Destination,
-			DepartureTime: depTime,
-			ArrivalTime:   arrTime,
-			Duration:      duration,
-			Price:         f.Fare.TotalPrice,
-			Currency:      f.Fare.CurrencyCode,
-			Stops:         f.NumberOfStops,
-			Aircraft:      f.AircraftModel,
-			Provider:      b.GetName(),
+			ID:             f.FlightNumber,
+			Airline:        f.AirlineName,
+			FlightNumber:   f.FlightNumber,
+			Origin:         f.Origin,
+			Destination:    f.Destination,
+			DepartureTime:  depTime,
+			ArrivalTime:    arrTime,
+			Duration:       duration,
+			Price:          f.Fare.TotalPrice,
+			Currency:       f.Fare.CurrencyCode,
+			Stops:          f.NumberOfStops,
+			Aircraft:       f.AircraftModel,
+			AvailableSeats: f.SeatsAvailable,
+			Amenities:      normalizeAmenities(f.OnboardServices),
+			Baggage:        parseBaggageInfo(f.BaggageInfo),
+			Provider:       b.GetName(),
 		}
 		flights = append(flights, flight)
 	}
@@ -3,9 +3,9 @@ package providers
 import (
 	"context"
 	"encoding/json"
+	"flight-aggregator/internal/apperror"
 	"flight-aggregator/internal/models"
 	"flight-aggregator/internal/utils"
-	"fmt"
 	"math/rand"
 	"os"
 	"time"
@@ -39,7 +39,29 @@ type GarudaResponse struct {
 			Amount   float64 `json:"amount"`
 			Currency string  `json:"currency"`
 		} `json:"price"`
-		FareClass string `json:"fare_class"`
+		FareClass      string `json:"fare_class"`
+		FareRules      *struct {
+			Refundable bool `json:"refundable"`
+			Changeable bool `json:"changeable"`
+		} `json:"fare_rules"`
+		AvailableSeats int    `json:"available_seats"`
+		Baggage        struct {
+			CarryOn int `json:"carry_on"`
+			Checked int `json:"checked"`
+		} `json:"baggage"`
+		Amenities []string `json:"amenities"`
+		Segments  []struct {
+			FlightNumber string `json:"flight_number"`
+			Departure    struct {
+				Airport string `json:"airport"`
+				Time    string `json:"time"`
+			} `json:"departure"`
+			Arrival struct {
+				Airport string `json:"airport"`
+				Time    string `json:"time"`
+			} `json:"arrival"`
+			LayoverMinutes int `json:"layover_minutes"`
+		} `json:"segments"`
 	} `json:"flights"`
 }
 
@@ -59,11 +81,15 @@ func (g *GarudaProvider) GetName() string {
 
 func (g *GarudaProvider) GetFlights(ctx context.Context, req models.SearchRequest) ([]models.Flight, error) {
 	if g == nil {
-		return nil, fmt.Errorf("PROVIDER_ERROR: Garuda provider not initialized")
+		return nil, apperror.New(apperror.CodeProvider, "Garuda provider not initialized")
 	}
 	// Simulate 50-100ms delay for Garuda Indonesia
 	delay := 50 + rand.Intn(51) // 50-100ms
-	time.Sleep(time.Duration(delay) * time.Millisecond)
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(time.Duration(delay) * time.Millisecond):
+	}
 
 	// Try different paths for mock data file
 	paths := []string{
@@ -81,12 +107,12 @@ func (g *GarudaProvider) GetFlights(ctx context.Context, req models.SearchReques
 		}
 	}
 	if err != nil {
-		return nil, fmt.Errorf("PROVIDER_ERROR: Garuda Indonesia service unavailable")
+		return nil, apperror.New(apperror.CodeProvider, "Garuda Indonesia service unavailable")
 	}
 
 	var response GarudaResponse
 	if err := json.Unmarshal(data, &response); err != nil {
-		return nil, fmt.Errorf("PROVIDER_ERROR: Invalid response from Garuda Indonesia")
+		return nil, utils.Permanent(apperror.Wrap(apperror.CodeProvider, err, "Invalid response from Garuda Indonesia"))
 	}
 
 	var flights []models.Flight
@@ -99,19 +125,45 @@ func (g *GarudaProvider) GetFlights(ctx context.Context, req models.SearchReques
 		arrTime := g.dateUtil.ParseDateTimeWithFallback(f.Arrival.Time, g.dateUtil.GetTimezoneByAirport(f.Arrival.Airport))
 
 		flight := models.Flight{
-			ID:            f.FlightID,
-			Airline:       f.Airline,
-			FlightNumber:  f.AirlineCode + " " + f.FlightID[2:],
-			Origin:        f.Departure.Airport,
-			Destination:   f.Arrival.Airport,
-			DepartureTime: depTime,
-			ArrivalTime:   arrTime,
-			Duration:      f.DurationMinutes,
-			Price:         f.Price.Amount,
-			Currency:      f.Price.Currency,
-			Stops:         f.Stops,
-			Aircraft:      f.Aircraft,
-			Provider:      g.GetName(),
+			ID:             f.FlightID,
+			Airline:        f.Airline,
+			FlightNumber:   f.AirlineCode + " " + f.FlightID[2:],
+			Origin:         f.Departure.Airport,
+			Destination:    f.Arrival.Airport,
+			DepartureTime:  depTime,
+			ArrivalTime:    arrTime,
+			Duration:       f.DurationMinutes,
+			Price:          f.Price.Amount,
+			Currency:       f.Price.Currency,
+			Stops:          f.Stops,
+			Aircraft:       f.Aircraft,
+			AvailableSeats: f.AvailableSeats,
+			Amenities:      normalizeAmenities(f.Amenities),
+			// Garuda counts baggage in pieces
+			Baggage: models.BaggageAllowance{
+				CabinPieces:   f.Baggage.CarryOn,
+				CheckedPieces: f.Baggage.Checked,
+			},
+			Provider: g.GetName(),
+		}
+		// Fares without fare_rules keep unknown rules
+		if rules := f.FareRules; rules != nil {
+			flight.Refundable = &rules.Refundable
+			flight.Changeable = &rules.Changeable
+		}
+
+		// A segment's layover is spent at its departure airport, starting when the previous segment lands
+		for i, segment := range f.Segments {
+			if i == 0 || segment.LayoverMinutes <= 0 {
+				continue
+			}
+			prev := f.Segments[i-1]
+			start := g.dateUtil.ParseDateTimeWithFallback(prev.Arrival.Time, g.dateUtil.GetTimezoneByAirport(prev.Arrival.Airport))
+			flight.Layovers = append(flight.Layovers, models.Layover{
+				Airport: segment.Departure.Airport,
+				Minutes: segment.LayoverMinutes,
+				Start:   &start,
+			})
 		}
 		flights = append(flights, flight)
 	}
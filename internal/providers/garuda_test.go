@@ -44,4 +44,82 @@ func TestGarudaProvider_GetFlights(t *testing.T) {
 			t.Errorf("Expected currency 'IDR', got %s", flight.Currency)
 		}
 	}
-}
\ No newline at end of file
+}
+
+func TestGarudaProvider_GetFlights_Layovers(t *testing.T) {
+	provider := NewGarudaProvider()
+	req := models.SearchRequest{Origin: "CGK", Destination: "DPS", DepartureDate: "2025-12-15", Passengers: 1, CabinClass: "economy"}
+
+	flights, err := provider.GetFlights(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for _, flight := range flights {
+		if flight.ID != "GA315" {
+			continue
+		}
+		if len(flight.Layovers) != 1 {
+			t.Fatalf("Expected one layover, got %+v", flight.Layovers)
+		}
+		layover := flight.Layovers[0]
+		if layover.Airport != "SUB" || layover.Minutes != 105 || layover.Start == nil || layover.Start.Hour() != 15 {
+			t.Errorf("Expected a 105 minute layover in SUB from 15:30, got %+v", layover)
+		}
+		return
+	}
+	t.Error("Expected the connecting GA315 flight")
+}
+
+func TestGarudaProvider_GetFlights_Attributes(t *testing.T) {
+	provider := NewGarudaProvider()
+	req := models.SearchRequest{Origin: "CGK", Destination: "DPS", DepartureDate: "2025-12-15", Passengers: 1, CabinClass: "economy"}
+
+	flights, err := provider.GetFlights(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, flight := range flights {
+		if flight.ID != "GA400" {
+			continue
+		}
+		if flight.Baggage.CabinPieces != 1 || flight.Baggage.CheckedPieces != 2 {
+			t.Errorf("Expected 1 cabin and 2 checked pieces, got %+v", flight.Baggage)
+		}
+		if len(flight.Amenities) != 3 || flight.Amenities[0] != models.AmenityWifi {
+			t.Errorf("Expected wifi, meal and entertainment, got %v", flight.Amenities)
+		}
+		if flight.Refundable == nil || *flight.Refundable || flight.Changeable == nil || !*flight.Changeable {
+			t.Errorf("Expected a changeable, non-refundable fare, got refundable %v changeable %v", flight.Refundable, flight.Changeable)
+		}
+		return
+	}
+	t.Fatal("Expected GA400 in the mock data")
+}
+
+func TestGarudaProvider_GetFlights_FareRules(t *testing.T) {
+	provider := NewGarudaProvider()
+	req := models.SearchRequest{Origin: "CGK", Destination: "DPS", DepartureDate: "2025-12-15", Passengers: 1, CabinClass: "economy"}
+
+	flights, err := provider.GetFlights(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	seen := make(map[string]bool)
+	for _, flight := range flights {
+		seen[flight.ID] = true
+		switch flight.ID {
+		case "GA410":
+			if flight.Refundable == nil || !*flight.Refundable {
+				t.Errorf("Expected GA410 to be refundable, got %v", flight.Refundable)
+			}
+		case "GA315":
+			if flight.Refundable != nil || flight.Changeable != nil {
+				t.Error("Expected unknown fare rules without fare_rules in the payload")
+			}
+		}
+	}
+	if !seen["GA410"] || !seen["GA315"] {
+		t.Fatalf("Expected GA410 and GA315 in the mock data, got %v", seen)
+	}
+}
@@ -3,9 +3,9 @@ package providers
 import (
 	"context"
 	"encoding/json"
+	"flight-aggregator/internal/apperror"
 	"flight-aggregator/internal/models"
 	"flight-aggregator/internal/utils"
-	"fmt"
 	"math/rand"
 	"os"
 	"time"
@@ -40,11 +40,24 @@ type LionAirResponse struct {
 			FlightTime int  `json:"flight_time"`
 			IsDirect   bool `json:"is_direct"`
 			StopCount  int  `json:"stop_count,omitempty"`
+			Layovers   []struct {
+				Airport         string `json:"airport"`
+				DurationMinutes int    `json:"duration_minutes"`
+			} `json:"layovers"`
 			Pricing    struct {
 				Total    float64 `json:"total"`
 				Currency string  `json:"currency"`
 			} `json:"pricing"`
+			SeatsLeft int    `json:"seats_left"`
 			PlaneType string `json:"plane_type"`
+			Services  struct {
+				WifiAvailable    bool `json:"wifi_available"`
+				MealsIncluded    bool `json:"meals_included"`
+				BaggageAllowance struct {
+					Cabin string `json:"cabin"`
+					Hold  string `json:"hold"`
+				} `json:"baggage_allowance"`
+			} `json:"services"`
 		} `json:"available_flights"`
 	} `json:"data"`
 }
@@ -65,11 +78,15 @@ func (l *LionAirProvider) GetName() string {
 
 func (l *LionAirProvider) GetFlights(ctx context.Context, req models.SearchRequest) ([]models.Flight, error) {
 	if l == nil {
-		return nil, fmt.Errorf("PROVIDER_ERROR: Lion Air provider not initialized")
+		return nil, apperror.New(apperror.CodeProvider, "Lion Air provider not initialized")
 	}
 	// Simulate 100-200ms delay for Lion Air
 	delay := 100 + rand.Intn(101) // 100-200ms
-	time.Sleep(time.Duration(delay) * time.Millisecond)
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(time.Duration(delay) * time.Millisecond):
+	}
 
 	// Try different paths for mock data file
 	paths := []string{
@@ -87,12 +104,12 @@ func (l *LionAirProvider) GetFlights(ctx context.Context, req models.SearchReque
 		}
 	}
 	if err != nil {
-		return nil, fmt.Errorf("PROVIDER_ERROR: Lion Air service unavailable")
+		return nil, apperror.New(apperror.CodeProvider, "Lion Air service unavailable")
 	}
 
 	var response LionAirResponse
 	if err := json.Unmarshal(data, &response); err != nil {
-		return nil, fmt.Errorf("PROVIDER_ERROR: Invalid response from Lion Air")
+		return nil, utils.Permanent(apperror.Wrap(apperror.CodeProvider, err, "Invalid response from Lion Air"))
 	}
 
 	var flights []models.Flight
@@ -113,19 +130,37 @@ func (l *LionAirProvider) GetFlights(ctx context.Context, req models.SearchReque
 		}
 
 		flight := models.Flight{
-			ID:            f.ID,
-			Airline:       f.Carrier.Name,
-			FlightNumber:  f.ID,
-			Origin:        f.Route.From.Code,
-			Destination:   f.Route.To.Code,
-			DepartureTime: depTime,
-			ArrivalTime:   arrTime,
-			Duration:      f.FlightTime,
-			Price:         f.Pricing.Total,
-			Currency:      f.Pricing.Currency,
-			Stops:         stops,
-			Aircraft:      f.PlaneType,
-			Provider:      l.GetName(),
+			ID:             f.ID,
+			Airline:        f.Carrier.Name,
+			FlightNumber:   f.ID,
+			Origin:         f.Route.From.Code,
+			Destination:    f.Route.To.Code,
+			DepartureTime:  depTime,
+			ArrivalTime:    arrTime,
+			Duration:       f.FlightTime,
+			Price:          f.Pricing.Total,
+			Currency:       f.Pricing.Currency,
+			Stops:          stops,
+			Aircraft:       f.PlaneType,
+			AvailableSeats: f.SeatsLeft,
+			Baggage: models.BaggageAllowance{
+				CabinKg:   parseKg(f.Services.BaggageAllowance.Cabin),
+				CheckedKg: parseKg(f.Services.BaggageAllowance.Hold),
+			},
+			Provider: l.GetName(),
+		}
+		if f.Services.WifiAvailable {
+			flight.Amenities = append(flight.Amenities, models.AmenityWifi)
+		}
+		if f.Services.MealsIncluded {
+			flight.Amenities = append(flight.Amenities, models.AmenityMeal)
+		}
+		// Lion Air only reports how long a layover lasts, not when it starts
+		for _, layover := range f.Layovers {
+			flight.Layovers = append(flight.Layovers, models.Layover{
+				Airport: layover.Airport,
+				Minutes: layover.DurationMinutes,
+			})
 		}
 		flights = append(flights, flight)
 	}
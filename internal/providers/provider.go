@@ -3,6 +3,7 @@ package providers
 import (
 	"context"
 	"flight-aggregator/internal/models"
+	"fmt"
 )
 
 type Provider interface {
@@ -10,7 +11,50 @@ type Provider interface {
 	GetName() string
 }
 
+// Credentials authenticate calls to a provider's API, e.g. {"api_key": "..."}
+type Credentials map[string]string
+
 type ProviderConfig struct {
 	Name        string
 	SuccessRate float64
-}
\ No newline at end of file
+	Credentials Credentials
+}
+
+// Names lists every supported provider in search order
+func Names() []string {
+	return []string{"Garuda Indonesia", "Lion Air", "Batik Air", "AirAsia"}
+}
+
+// DefaultProviders returns every provider with the service's own credentials
+func DefaultProviders() []Provider {
+	providerList := make([]Provider, 0, len(Names()))
+	for _, name := range Names() {
+		p, _ := New(name, nil)
+		providerList = append(providerList, p)
+	}
+	return providerList
+}
+
+// New builds a provider by name, authenticated with the given credentials
+func New(name string, credentials Credentials) (Provider, error) {
+	switch name {
+	case "Garuda Indonesia":
+		p := NewGarudaProvider()
+		p.config.Credentials = credentials
+		return p, nil
+	case "Lion Air":
+		p := NewLionAirProvider()
+		p.config.Credentials = credentials
+		return p, nil
+	case "Batik Air":
+		p := NewBatikAirProvider()
+		p.config.Credentials = credentials
+		return p, nil
+	case "AirAsia":
+		p := NewAirAsiaProvider()
+		p.config.Credentials = credentials
+		return p, nil
+	default:
+		return nil, fmt.Errorf("unknown provider %s", name)
+	}
+}
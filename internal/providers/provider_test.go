@@ -142,4 +142,20 @@ func TestProviderInterface(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestNew(t *testing.T) {
+	for _, name := range Names() {
+		provider, err := New(name, Credentials{"api_key": "secret"})
+		if err != nil {
+			t.Fatalf("Expected provider %s, got error %v", name, err)
+		}
+		if provider.GetName() != name {
+			t.Errorf("Expected provider name %s, got %s", name, provider.GetName())
+		}
+	}
+
+	if _, err := New("Unknown Air", nil); err == nil {
+		t.Error("Expected error for unknown provider")
+	}
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flight-aggregator/internal/models"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	apiKeyPrefix  = "api_key:"
+	apiKeyIndex   = "api_keys"
+	apiKeyIDBytes = 8
+)
+
+// ErrAPIKeyNotFound is returned when a key does not exist or its hash does not match
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+type APIKeyRepository interface {
+	// Lookup finds the stored key for a raw key presented by a client
+	Lookup(ctx context.Context, rawKey string) (*models.APIKey, error)
+	Get(ctx context.Context, id string) (*models.APIKey, error)
+	Save(ctx context.Context, apiKey *models.APIKey) error
+	List(ctx context.Context) ([]models.APIKey, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type redisAPIKeyRepository struct {
+	client *redis.Client
+}
+
+// storedAPIKey is the persisted form of an API key, including its hash
+type storedAPIKey struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Plan      string    `json:"plan"`
+	TenantID  string    `json:"tenantId,omitempty"`
+	KeyHash   string    `json:"keyHash"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func NewRedisAPIKeyRepository(client *redis.Client) APIKeyRepository {
+	return &redisAPIKeyRepository{client: client}
+}
+
+// HashAPIKey returns the hex SHA-256 of a raw key
+func HashAPIKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// APIKeyID derives the public identifier of a key from its hash
+func APIKeyID(keyHash string) string {
+	return keyHash[:apiKeyIDBytes*2]
+}
+
+func (r *redisAPIKeyRepository) Lookup(ctx context.Context, rawKey string) (*models.APIKey, error) {
+	keyHash := HashAPIKey(rawKey)
+	apiKey, err := r.Get(ctx, APIKeyID(keyHash))
+	if err != nil {
+		return nil, err
+	}
+	if apiKey.KeyHash != keyHash {
+		return nil, ErrAPIKeyNotFound
+	}
+	return apiKey, nil
+}
+
+func (r *redisAPIKeyRepository) Get(ctx context.Context, id string) (*models.APIKey, error) {
+	data, err := r.client.Get(ctx, apiKeyPrefix+id).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var stored storedAPIKey
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("invalid stored api key %s: %w", id, err)
+	}
+	apiKey := models.APIKey(stored)
+	return &apiKey, nil
+}
+
+func (r *redisAPIKeyRepository) Save(ctx context.Context, apiKey *models.APIKey) error {
+	data, err := json.Marshal(storedAPIKey(*apiKey))
+	if err != nil {
+		return err
+	}
+
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, apiKeyPrefix+apiKey.ID, data, 0)
+		pipe.SAdd(ctx, apiKeyIndex, apiKey.ID)
+		return nil
+	})
+	return err
+}
+
+func (r *redisAPIKeyRepository) List(ctx context.Context) ([]models.APIKey, error) {
+	ids, err := r.client.SMembers(ctx, apiKeyIndex).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	apiKeys := make([]models.APIKey, 0, len(ids))
+	for _, id := range ids {
+		apiKey, err := r.Get(ctx, id)
+		if errors.Is(err, ErrAPIKeyNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		apiKeys = append(apiKeys, *apiKey)
+	}
+	return apiKeys, nil
+}
+
+func (r *redisAPIKeyRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, apiKeyPrefix+id)
+		pipe.SRem(ctx, apiKeyIndex, id)
+		return nil
+	})
+	return err
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flight-aggregator/internal/models"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	promoPrefix     = "promo:"
+	promoUsesPrefix = "promo_uses:"
+	promoIndex      = "promos"
+)
+
+// ErrPromoNotFound is returned when a promo code does not exist
+var ErrPromoNotFound = errors.New("promo code not found")
+
+// PromoRepository is the promo code store. Codes are looked up as stored, callers
+// normalize them.
+type PromoRepository interface {
+	Get(ctx context.Context, code string) (*models.PromoCode, error)
+	Save(ctx context.Context, promo *models.PromoCode) error
+	List(ctx context.Context) ([]models.PromoCode, error)
+	Delete(ctx context.Context, code string) error
+	// IncrementUsage records one more search the code was applied to
+	IncrementUsage(ctx context.Context, code string) error
+}
+
+type redisPromoRepository struct {
+	client *redis.Client
+}
+
+func NewRedisPromoRepository(client *redis.Client) PromoRepository {
+	return &redisPromoRepository{client: client}
+}
+
+// Get returns the promo with its usage count, which lives in a separate counter
+// so saving a promo never resets it
+func (r *redisPromoRepository) Get(ctx context.Context, code string) (*models.PromoCode, error) {
+	values, err := r.client.MGet(ctx, promoPrefix+code, promoUsesPrefix+code).Result()
+	if err != nil {
+		return nil, err
+	}
+	data, ok := values[0].(string)
+	if !ok {
+		return nil, ErrPromoNotFound
+	}
+
+	var promo models.PromoCode
+	if err := json.Unmarshal([]byte(data), &promo); err != nil {
+		return nil, fmt.Errorf("invalid stored promo code %s: %w", code, err)
+	}
+	promo.UsageCount = counterValue(values[1])
+	return &promo, nil
+}
+
+func (r *redisPromoRepository) Save(ctx context.Context, promo *models.PromoCode) error {
+	stored := *promo
+	stored.UsageCount = 0
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, promoPrefix+promo.Code, data, 0)
+		pipe.SAdd(ctx, promoIndex, promo.Code)
+		return nil
+	})
+	return err
+}
+
+func (r *redisPromoRepository) List(ctx context.Context) ([]models.PromoCode, error) {
+	codes, err := r.client.SMembers(ctx, promoIndex).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	promos := make([]models.PromoCode, 0, len(codes))
+	for _, code := range codes {
+		promo, err := r.Get(ctx, code)
+		if errors.Is(err, ErrPromoNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		promos = append(promos, *promo)
+	}
+	return promos, nil
+}
+
+func (r *redisPromoRepository) Delete(ctx context.Context, code string) error {
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, promoPrefix+code, promoUsesPrefix+code)
+		pipe.SRem(ctx, promoIndex, code)
+		return nil
+	})
+	return err
+}
+
+func (r *redisPromoRepository) IncrementUsage(ctx context.Context, code string) error {
+	return r.client.Incr(ctx, promoUsesPrefix+code).Err()
+}
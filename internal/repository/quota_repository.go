@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"flight-aggregator/internal/models"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Counters are kept a while after their period ends so past usage can be audited
+const (
+	dailyQuotaRetention   = 3 * 24 * time.Hour
+	monthlyQuotaRetention = 93 * 24 * time.Hour
+)
+
+// QuotaLimits are the search quotas of a client, 0 means unlimited
+type QuotaLimits struct {
+	Daily   int
+	Monthly int
+}
+
+type QuotaRepository interface {
+	// Consume counts one search unless it would exceed a quota
+	Consume(ctx context.Context, clientID string, limits QuotaLimits, now time.Time) (*models.Usage, error)
+	// Release gives back a search counted at now
+	Release(ctx context.Context, clientID string, now time.Time) error
+	Usage(ctx context.Context, clientID string, limits QuotaLimits, now time.Time) (*models.Usage, error)
+}
+
+type redisQuotaRepository struct {
+	client *redis.Client
+}
+
+// consumeQuotaScript checks both periods before counting, so a rejected search
+// never consumes quota. It returns {daily used, monthly used, exceeded period}
+// where exceeded is 0 (none), 1 (daily) or 2 (monthly).
+var consumeQuotaScript = redis.NewScript(`
+local daily = KEYS[1]
+local monthly = KEYS[2]
+local daily_limit = tonumber(ARGV[1])
+local monthly_limit = tonumber(ARGV[2])
+local daily_ttl = tonumber(ARGV[3])
+local monthly_ttl = tonumber(ARGV[4])
+
+local daily_used = tonumber(redis.call('GET', daily) or '0')
+local monthly_used = tonumber(redis.call('GET', monthly) or '0')
+if monthly_limit > 0 and monthly_used >= monthly_limit then
+	return {daily_used, monthly_used, 2}
+end
+if daily_limit > 0 and daily_used >= daily_limit then
+	return {daily_used, monthly_used, 1}
+end
+
+daily_used = redis.call('INCR', daily)
+if daily_used == 1 then
+	redis.call('PEXPIRE', daily, daily_ttl)
+end
+monthly_used = redis.call('INCR', monthly)
+if monthly_used == 1 then
+	redis.call('PEXPIRE', monthly, monthly_ttl)
+end
+return {daily_used, monthly_used, 0}
+`)
+
+// releaseQuotaScript gives back one search without taking a counter below zero
+var releaseQuotaScript = redis.NewScript(`
+for _, key in ipairs(KEYS) do
+	if tonumber(redis.call('GET', key) or '0') > 0 then
+		redis.call('DECR', key)
+	end
+end
+return 0
+`)
+
+func NewRedisQuotaRepository(client *redis.Client) QuotaRepository {
+	return &redisQuotaRepository{client: client}
+}
+
+func (r *redisQuotaRepository) Consume(ctx context.Context, clientID string, limits QuotaLimits, now time.Time) (*models.Usage, error) {
+	dailyKey, monthlyKey := quotaKeys(clientID, now)
+	dayEnd, monthEnd := quotaResets(now)
+
+	values, err := consumeQuotaScript.Run(ctx, r.client, []string{dailyKey, monthlyKey},
+		limits.Daily, limits.Monthly,
+		time.Until(dayEnd.Add(dailyQuotaRetention)).Milliseconds(),
+		time.Until(monthEnd.Add(monthlyQuotaRetention)).Milliseconds()).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != 3 {
+		return nil, fmt.Errorf("unexpected quota script result: %v", values)
+	}
+
+	usage := newUsage(clientID, limits, int(values[0]), int(values[1]), dayEnd, monthEnd)
+	switch values[2] {
+	case 1:
+		usage.Exceeded = models.QuotaPeriodDaily
+	case 2:
+		usage.Exceeded = models.QuotaPeriodMonthly
+	}
+	return usage, nil
+}
+
+func (r *redisQuotaRepository) Release(ctx context.Context, clientID string, now time.Time) error {
+	dailyKey, monthlyKey := quotaKeys(clientID, now)
+	return releaseQuotaScript.Run(ctx, r.client, []string{dailyKey, monthlyKey}).Err()
+}
+
+func (r *redisQuotaRepository) Usage(ctx context.Context, clientID string, limits QuotaLimits, now time.Time) (*models.Usage, error) {
+	dailyKey, monthlyKey := quotaKeys(clientID, now)
+	dayEnd, monthEnd := quotaResets(now)
+
+	values, err := r.client.MGet(ctx, dailyKey, monthlyKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	return newUsage(clientID, limits, counterValue(values[0]), counterValue(values[1]), dayEnd, monthEnd), nil
+}
+
+// quotaKeys embeds the period in the key, so counters roll over on their own
+func quotaKeys(clientID string, now time.Time) (string, string) {
+	utcNow := now.UTC()
+	return fmt.Sprintf("quota:%s:day:%s", clientID, utcNow.Format("20060102")),
+		fmt.Sprintf("quota:%s:month:%s", clientID, utcNow.Format("200601"))
+}
+
+// quotaResets returns when the current UTC day and month end
+func quotaResets(now time.Time) (time.Time, time.Time) {
+	utcNow := now.UTC()
+	dayStart := time.Date(utcNow.Year(), utcNow.Month(), utcNow.Day(), 0, 0, 0, 0, time.UTC)
+	monthStart := time.Date(utcNow.Year(), utcNow.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return dayStart.AddDate(0, 0, 1), monthStart.AddDate(0, 1, 0)
+}
+
+func newUsage(clientID string, limits QuotaLimits, dailyUsed, monthlyUsed int, dayEnd, monthEnd time.Time) *models.Usage {
+	return &models.Usage{
+		Client: clientID,
+		Daily: models.QuotaPeriod{
+			Used:    dailyUsed,
+			Limit:   limits.Daily,
+			ResetAt: dayEnd,
+		},
+		Monthly: models.QuotaPeriod{
+			Used:    monthlyUsed,
+			Limit:   limits.Monthly,
+			ResetAt: monthEnd,
+		},
+	}
+}
+
+func counterValue(value interface{}) int {
+	var count int
+	if s, ok := value.(string); ok {
+		fmt.Sscanf(s, "%d", &count)
+	}
+	return count
+}
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"log"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// NewRedisClient connects to Redis, accepting either a redis:// URL with
+// credentials or a plain host:port address
+func NewRedisClient(addr string) *redis.Client {
+	if strings.HasPrefix(addr, "redis://") {
+		opt, err := redis.ParseURL(addr)
+		if err != nil {
+			log.Printf("Failed to parse Redis URL: %v, falling back to simple connection", err)
+			return redis.NewClient(&redis.Options{
+				Addr: "localhost:6379",
+				DB:   0,
+			})
+		}
+		return redis.NewClient(opt)
+	}
+
+	return redis.NewClient(&redis.Options{
+		Addr: addr,
+		DB:   0,
+	})
+}
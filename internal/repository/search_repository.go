@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flight-aggregator/internal/models"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const searchPrefix = "search:"
+
+// ErrSearchNotFound is returned when a search does not exist or has expired
+var ErrSearchNotFound = errors.New("search not found")
+
+// SearchRepository stores search sessions until they expire
+type SearchRepository interface {
+	// Save stores the session until its ExpiresAt
+	Save(ctx context.Context, session *models.SearchSession) error
+	Get(ctx context.Context, id string) (*models.SearchSession, error)
+}
+
+type redisSearchRepository struct {
+	client *redis.Client
+}
+
+func NewRedisSearchRepository(client *redis.Client) SearchRepository {
+	return &redisSearchRepository{client: client}
+}
+
+func (r *redisSearchRepository) Save(ctx context.Context, session *models.SearchSession) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, searchPrefix+session.ID, data, time.Until(session.ExpiresAt)).Err()
+}
+
+func (r *redisSearchRepository) Get(ctx context.Context, id string) (*models.SearchSession, error) {
+	data, err := r.client.Get(ctx, searchPrefix+id).Bytes()
+	if err == redis.Nil {
+		return nil, ErrSearchNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var session models.SearchSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("invalid stored search %s: %w", id, err)
+	}
+	return &session, nil
+}
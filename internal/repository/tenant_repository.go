@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flight-aggregator/internal/models"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	tenantPrefix = "tenant:"
+	tenantIndex  = "tenants"
+)
+
+// ErrTenantNotFound is returned when a tenant does not exist
+var ErrTenantNotFound = errors.New("tenant not found")
+
+// TenantRepository is the tenant config store
+type TenantRepository interface {
+	Get(ctx context.Context, id string) (*models.Tenant, error)
+	Save(ctx context.Context, tenant *models.Tenant) error
+	List(ctx context.Context) ([]models.Tenant, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type redisTenantRepository struct {
+	client *redis.Client
+}
+
+func NewRedisTenantRepository(client *redis.Client) TenantRepository {
+	return &redisTenantRepository{client: client}
+}
+
+func (r *redisTenantRepository) Get(ctx context.Context, id string) (*models.Tenant, error) {
+	data, err := r.client.Get(ctx, tenantPrefix+id).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrTenantNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var tenant models.Tenant
+	if err := json.Unmarshal(data, &tenant); err != nil {
+		return nil, fmt.Errorf("invalid stored tenant %s: %w", id, err)
+	}
+	return &tenant, nil
+}
+
+func (r *redisTenantRepository) Save(ctx context.Context, tenant *models.Tenant) error {
+	data, err := json.Marshal(tenant)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, tenantPrefix+tenant.ID, data, 0)
+		pipe.SAdd(ctx, tenantIndex, tenant.ID)
+		return nil
+	})
+	return err
+}
+
+func (r *redisTenantRepository) List(ctx context.Context) ([]models.Tenant, error) {
+	ids, err := r.client.SMembers(ctx, tenantIndex).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	tenants := make([]models.Tenant, 0, len(ids))
+	for _, id := range ids {
+		tenant, err := r.Get(ctx, id)
+		if errors.Is(err, ErrTenantNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		tenants = append(tenants, *tenant)
+	}
+	return tenants, nil
+}
+
+func (r *redisTenantRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, tenantPrefix+id)
+		pipe.SRem(ctx, tenantIndex, id)
+		return nil
+	})
+	return err
+}
@@ -2,82 +2,211 @@ package service
 
 import (
 	"context"
+	"errors"
+	"flight-aggregator/internal/apperror"
 	"flight-aggregator/internal/config"
 	"flight-aggregator/internal/models"
 	"flight-aggregator/internal/providers"
 	"flight-aggregator/internal/utils"
-	"fmt"
 	"log"
 	"sync"
+	"time"
 )
 
 type FlightService interface {
-	GetAllFlights(ctx context.Context, req models.SearchRequest) ([]models.Flight, error)
+	// GetAllFlights queries the tenant's providers, every provider when tenant is nil
+	GetAllFlights(ctx context.Context, req models.SearchRequest, tenant *models.Tenant) (*SearchResult, error)
+}
+
+// SearchResult is the aggregated outcome of querying every provider
+type SearchResult struct {
+	Flights   []models.Flight
+	Providers []models.ProviderStatus
 }
 
 type flightService struct {
-	providers []providers.Provider
-	retryUtil *utils.RetryUtil
+	providers   []providers.Provider
+	newProvider func(name string, credentials providers.Credentials) (providers.Provider, error)
+	retryUtil   *utils.RetryUtil
+	retryUtils  map[string]*utils.RetryUtil
+	hedgeUtils  map[string]*utils.HedgeUtil
+	bulkheads   map[string]*utils.Bulkhead
 }
 
 func NewFlightService() FlightService {
 	cfg := config.MustLoad()
+	providerList := providers.DefaultProviders()
+
+	// One budget shared by all providers so an outage can't multiply load
+	budget := utils.NewRetryBudget(cfg.RetryBudgetRatio, cfg.RetryBudgetMinRetries)
+	retryUtils := make(map[string]*utils.RetryUtil, len(providerList))
+	hedgeUtils := make(map[string]*utils.HedgeUtil, len(providerList))
+	bulkheads := make(map[string]*utils.Bulkhead, len(providerList))
+	for _, p := range providerList {
+		settings := cfg.ForProvider(p.GetName())
+		retryUtils[p.GetName()] = utils.NewRetryUtilWithPolicy(utils.RetryPolicy{
+			MaxRetries: settings.MaxRetries,
+			BaseDelay:  settings.RetryDelay,
+			MaxDelay:   settings.RetryMaxDelay,
+		}, budget)
+		hedgeUtils[p.GetName()] = utils.NewHedgeUtil(utils.HedgePolicy{
+			Enabled:    settings.HedgeEnabled,
+			Percentile: settings.HedgePercentile,
+			MaxRatio:   settings.HedgeMaxRatio,
+		})
+		bulkheads[p.GetName()] = utils.NewBulkhead(settings.BulkheadMaxConcurrent, settings.BulkheadQueueTimeout)
+	}
+
 	return &flightService{
-		providers: []providers.Provider{
-			providers.NewGarudaProvider(),
-			providers.NewLionAirProvider(),
-			providers.NewBatikAirProvider(),
-			providers.NewAirAsiaProvider(),
-		},
-		retryUtil: utils.NewRetryUtil(cfg.MaxRetries, cfg.RetryDelay),
+		providers:   providerList,
+		newProvider: providers.New,
+		retryUtil:   utils.NewRetryUtil(cfg.MaxRetries, cfg.RetryDelay),
+		retryUtils:  retryUtils,
+		hedgeUtils:  hedgeUtils,
+		bulkheads:   bulkheads,
+	}
+}
+
+// retryFor returns the retry policy of a provider, falling back to the default one
+func (fs *flightService) retryFor(name string) *utils.RetryUtil {
+	if ru, ok := fs.retryUtils[name]; ok {
+		return ru
+	}
+	return fs.retryUtil
+}
+
+// callProvider fetches flights from a provider, hedging the call when configured.
+// Every call, hedges included, must hold a slot of the provider's bulkhead.
+func (fs *flightService) callProvider(ctx context.Context, p providers.Provider, req models.SearchRequest) ([]models.Flight, error) {
+	call := func(ctx context.Context) ([]models.Flight, error) {
+		if bulkhead, ok := fs.bulkheads[p.GetName()]; ok {
+			release, err := bulkhead.Acquire(ctx)
+			if err != nil {
+				return nil, err
+			}
+			defer release()
+		}
+		return p.GetFlights(ctx, req)
 	}
+
+	hedgeUtil, ok := fs.hedgeUtils[p.GetName()]
+	if !ok {
+		return call(ctx)
+	}
+
+	result, err := hedgeUtil.Execute(ctx, func(ctx context.Context) (interface{}, error) {
+		return call(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	flights, _ := result.([]models.Flight)
+	return flights, nil
 }
 
-func (fs *flightService) GetAllFlights(ctx context.Context, req models.SearchRequest) ([]models.Flight, error) {
-	if fs.providers == nil {
-		return nil, fmt.Errorf("INTERNAL_ERROR: No providers configured")
+// providersFor selects the tenant's enabled providers. Providers the tenant has
+// credentials for are built per request so no tenant calls with another's account.
+// Retries, hedging and bulkheads stay shared per provider, as they protect the
+// provider rather than the tenant.
+func (fs *flightService) providersFor(tenant *models.Tenant) ([]providers.Provider, error) {
+	if tenant == nil {
+		return fs.providers, nil
+	}
+
+	var selected []providers.Provider
+	for _, p := range fs.providers {
+		if !tenant.EnablesProvider(p.GetName()) {
+			continue
+		}
+		credentials, ok := tenant.ProviderCredentials[p.GetName()]
+		if !ok || fs.newProvider == nil {
+			selected = append(selected, p)
+			continue
+		}
+		tenantProvider, err := fs.newProvider(p.GetName(), credentials)
+		if err != nil {
+			return nil, apperror.Wrapf(apperror.CodeInternal, err, "Failed to configure %s for tenant %s", p.GetName(), tenant.ID)
+		}
+		selected = append(selected, tenantProvider)
+	}
+	return selected, nil
+}
+
+func (fs *flightService) GetAllFlights(ctx context.Context, req models.SearchRequest, tenant *models.Tenant) (*SearchResult, error) {
+	providerList, err := fs.providersFor(tenant)
+	if err != nil {
+		return nil, err
+	}
+	if len(providerList) == 0 {
+		return nil, apperror.New(apperror.CodeInternal, "No providers configured")
 	}
 
 	var allFlights []models.Flight
 	var mu sync.Mutex
 	var wg sync.WaitGroup
 	var errorCount int
+	statuses := make([]models.ProviderStatus, len(providerList))
 
-	for _, provider := range fs.providers {
+	for i, provider := range providerList {
 		wg.Add(1)
-		go func(p providers.Provider) {
+		go func(i int, p providers.Provider) {
 			defer wg.Done()
-			
-			// Retry with exponential backoff
-			err := fs.retryUtil.ExecuteWithRetry(ctx, func() error {
-				flights, err := p.GetFlights(ctx, req)
+
+			start := time.Now()
+			var flights []models.Flight
+
+			// Retry transient errors with jittered exponential backoff
+			attempts, err := fs.retryFor(p.GetName()).ExecuteWithRetryAttempts(ctx, func() error {
+				result, err := fs.callProvider(ctx, p, req)
 				if err != nil {
 					return err
 				}
-				
-				mu.Lock()
-				allFlights = append(allFlights, flights...)
-				mu.Unlock()
+				flights = result
 				return nil
 			})
-			
+
+			status := models.ProviderStatus{
+				Name:        p.GetName(),
+				Status:      models.ProviderStatusSuccess,
+				LatencyMs:   int(time.Since(start).Milliseconds()),
+				Attempts:    attempts,
+				FlightCount: len(flights),
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
 			if err != nil {
 				log.Printf("Error fetching flights from %s after retries: %v", p.GetName(), err)
-				mu.Lock()
 				errorCount++
-				mu.Unlock()
+				status.Status, status.ErrorCode = classifyProviderError(err)
+			} else {
+				allFlights = append(allFlights, flights...)
 			}
-		}(provider)
+			statuses[i] = status
+		}(i, provider)
 	}
 
 	wg.Wait()
 
-	if len(allFlights) == 0 && errorCount == len(fs.providers) {
-		return nil, fmt.Errorf("SERVICE_ERROR: All flight providers are currently unavailable")
+	if len(allFlights) == 0 && errorCount == len(providerList) {
+		return nil, apperror.New(apperror.CodeService, "")
 	}
 
-
-
-	return allFlights, nil
+	return &SearchResult{
+		Flights:   allFlights,
+		Providers: statuses,
+	}, nil
 }
 
+// classifyProviderError maps a provider failure to its status and error code
+func classifyProviderError(err error) (string, string) {
+	switch {
+	case errors.Is(err, apperror.ErrProviderThrottled):
+		return models.ProviderStatusThrottled, apperror.CodeProviderThrottled
+	case errors.Is(err, apperror.ErrProviderTimeout), errors.Is(err, context.DeadlineExceeded):
+		return models.ProviderStatusTimeout, apperror.CodeProviderTimeout
+	default:
+		return models.ProviderStatusFailed, apperror.CodeProvider
+	}
+}
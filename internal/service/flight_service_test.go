@@ -100,7 +100,7 @@ func TestFlightService_GetAllFlights(t *testing.T) {
 				retryUtil: &utils.RetryUtil{},
 			}
 
-			result, err := fs.GetAllFlights(context.Background(), tt.req)
+			result, err := fs.GetAllFlights(context.Background(), tt.req, nil)
 
 			if tt.expectError && err == nil {
 				t.Error("Expected error but got none")
@@ -108,10 +108,108 @@ func TestFlightService_GetAllFlights(t *testing.T) {
 			if !tt.expectError && err != nil {
 				t.Errorf("Unexpected error: %v", err)
 			}
-			if len(result) != tt.expectedCount {
-				t.Errorf("Expected %d flights, got %d", tt.expectedCount, len(result))
+			var count int
+			if result != nil {
+				count = len(result.Flights)
+			}
+			if count != tt.expectedCount {
+				t.Errorf("Expected %d flights, got %d", tt.expectedCount, count)
 			}
 		})
 	}
 }
 
+func TestFlightService_GetAllFlights_BulkheadFull(t *testing.T) {
+	bulkhead := utils.NewBulkhead(1, 0)
+	release, _ := bulkhead.Acquire(context.Background())
+	defer release()
+
+	fs := &flightService{
+		providers: []providers.Provider{
+			&mockProvider{name: "Garuda", flights: []models.Flight{{ID: "1"}}},
+			&mockProvider{name: "Lion Air", flights: []models.Flight{{ID: "2"}}},
+		},
+		retryUtil: &utils.RetryUtil{},
+		bulkheads: map[string]*utils.Bulkhead{"Lion Air": bulkhead},
+	}
+
+	result, err := fs.GetAllFlights(context.Background(), models.SearchRequest{Origin: "CGK", Destination: "DPS"}, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Flights) != 1 {
+		t.Errorf("Expected 1 flight, got %d", len(result.Flights))
+	}
+	if result.Providers[1].Status != models.ProviderStatusThrottled {
+		t.Errorf("Expected Lion Air to be throttled, got %s", result.Providers[1].Status)
+	}
+}
+
+func TestFlightService_GetAllFlights_ProviderStatuses(t *testing.T) {
+	fs := &flightService{
+		providers: []providers.Provider{
+			&mockProvider{name: "Garuda", flights: []models.Flight{}},
+			&mockProvider{name: "Lion Air", err: errors.New("PROVIDER_ERROR: unavailable")},
+			&mockProvider{name: "Batik Air", flights: []models.Flight{{ID: "1"}, {ID: "2"}}},
+		},
+		retryUtil: &utils.RetryUtil{},
+	}
+
+	result, err := fs.GetAllFlights(context.Background(), models.SearchRequest{Origin: "CGK", Destination: "DPS"}, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []models.ProviderStatus{
+		{Name: "Garuda", Status: models.ProviderStatusSuccess, Attempts: 1, FlightCount: 0},
+		{Name: "Lion Air", Status: models.ProviderStatusFailed, Attempts: 1, ErrorCode: "PROVIDER_ERROR"},
+		{Name: "Batik Air", Status: models.ProviderStatusSuccess, Attempts: 1, FlightCount: 2},
+	}
+	for i, want := range expected {
+		got := result.Providers[i]
+		got.LatencyMs = 0
+		if got != want {
+			t.Errorf("Provider %d: expected %+v, got %+v", i, want, got)
+		}
+	}
+}
+
+func TestFlightService_GetAllFlights_Tenant(t *testing.T) {
+	var builtWith providers.Credentials
+	fs := &flightService{
+		providers: []providers.Provider{
+			&mockProvider{name: "Garuda", flights: []models.Flight{{ID: "1"}}},
+			&mockProvider{name: "Lion Air", flights: []models.Flight{{ID: "2"}}},
+			&mockProvider{name: "Batik Air", flights: []models.Flight{{ID: "3"}}},
+		},
+		newProvider: func(name string, credentials providers.Credentials) (providers.Provider, error) {
+			builtWith = credentials
+			return &mockProvider{name: name, flights: []models.Flight{{ID: "tenant-" + name}}}, nil
+		},
+		retryUtil: &utils.RetryUtil{},
+	}
+	tenant := &models.Tenant{
+		ID:                  "acme",
+		EnabledProviders:    []string{"Garuda", "Batik Air"},
+		ProviderCredentials: map[string]map[string]string{"Batik Air": {"api_key": "acme-key"}},
+	}
+
+	result, err := fs.GetAllFlights(context.Background(), models.SearchRequest{Origin: "CGK", Destination: "DPS"}, tenant)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(result.Providers) != 2 || result.Providers[0].Name != "Garuda" || result.Providers[1].Name != "Batik Air" {
+		t.Fatalf("Expected only the tenant's providers, got %+v", result.Providers)
+	}
+	if builtWith["api_key"] != "acme-key" {
+		t.Errorf("Expected Batik Air to be built with the tenant's credentials, got %v", builtWith)
+	}
+	ids := map[string]bool{}
+	for _, flight := range result.Flights {
+		ids[flight.ID] = true
+	}
+	if !ids["1"] || !ids["tenant-Batik Air"] || ids["2"] {
+		t.Errorf("Unexpected flights %v", ids)
+	}
+}
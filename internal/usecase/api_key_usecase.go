@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"flight-aggregator/internal/apperror"
+	"flight-aggregator/internal/models"
+	"flight-aggregator/internal/repository"
+	"sort"
+	"time"
+)
+
+const apiKeyPrefix = "fa_"
+
+type APIKeyUsecase interface {
+	CreateKey(ctx context.Context, req models.CreateAPIKeyRequest) (*models.CreateAPIKeyResponse, error)
+	ListKeys(ctx context.Context) ([]models.APIKey, error)
+	RevokeKey(ctx context.Context, id string) error
+	ListPlans(ctx context.Context) ([]models.Plan, error)
+}
+
+type apiKeyUsecase struct {
+	repository repository.APIKeyRepository
+	tenants    repository.TenantRepository
+	plans      map[string]models.Plan
+}
+
+func NewAPIKeyUsecase(repo repository.APIKeyRepository, tenants repository.TenantRepository, plans map[string]models.Plan) APIKeyUsecase {
+	return &apiKeyUsecase{
+		repository: repo,
+		tenants:    tenants,
+		plans:      plans,
+	}
+}
+
+func (ku *apiKeyUsecase) CreateKey(ctx context.Context, req models.CreateAPIKeyRequest) (*models.CreateAPIKeyResponse, error) {
+	if _, ok := ku.plans[req.Plan]; !ok {
+		return nil, apperror.Newf(apperror.CodeValidation, "Unknown plan %s", req.Plan)
+	}
+	if req.TenantID != "" {
+		_, err := ku.tenants.Get(ctx, req.TenantID)
+		if err == repository.ErrTenantNotFound {
+			return nil, apperror.Newf(apperror.CodeValidation, "Unknown tenant %s", req.TenantID)
+		}
+		if err != nil {
+			return nil, apperror.Wrap(apperror.CodeService, err, "Failed to load tenant")
+		}
+	}
+
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, apperror.New(apperror.CodeInternal, "Failed to generate API key")
+	}
+	rawKey := apiKeyPrefix + hex.EncodeToString(secret)
+	keyHash := repository.HashAPIKey(rawKey)
+
+	apiKey := models.APIKey{
+		ID:        repository.APIKeyID(keyHash),
+		Name:      req.Name,
+		Plan:      req.Plan,
+		TenantID:  req.TenantID,
+		KeyHash:   keyHash,
+		Active:    true,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := ku.repository.Save(ctx, &apiKey); err != nil {
+		return nil, apperror.Wrap(apperror.CodeService, err, "Failed to store API key")
+	}
+
+	return &models.CreateAPIKeyResponse{APIKey: apiKey, Key: rawKey}, nil
+}
+
+func (ku *apiKeyUsecase) ListKeys(ctx context.Context) ([]models.APIKey, error) {
+	apiKeys, err := ku.repository.List(ctx)
+	if err != nil {
+		return nil, apperror.Wrap(apperror.CodeService, err, "Failed to list API keys")
+	}
+	sort.Slice(apiKeys, func(i, j int) bool {
+		return apiKeys[i].CreatedAt.Before(apiKeys[j].CreatedAt)
+	})
+	return apiKeys, nil
+}
+
+// RevokeKey deactivates a key but keeps its record for auditing
+func (ku *apiKeyUsecase) RevokeKey(ctx context.Context, id string) error {
+	apiKey, err := ku.repository.Get(ctx, id)
+	if err == repository.ErrAPIKeyNotFound {
+		return apperror.Newf(apperror.CodeNotFound, "API key %s not found", id)
+	}
+	if err != nil {
+		return apperror.Wrap(apperror.CodeService, err, "Failed to load API key")
+	}
+
+	apiKey.Active = false
+	if err := ku.repository.Save(ctx, apiKey); err != nil {
+		return apperror.Wrap(apperror.CodeService, err, "Failed to revoke API key")
+	}
+	return nil
+}
+
+func (ku *apiKeyUsecase) ListPlans(ctx context.Context) ([]models.Plan, error) {
+	plans := make([]models.Plan, 0, len(ku.plans))
+	for _, plan := range ku.plans {
+		plans = append(plans, plan)
+	}
+	sort.Slice(plans, func(i, j int) bool {
+		return plans[i].RequestsPerSecond < plans[j].RequestsPerSecond
+	})
+	return plans, nil
+}
@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"flight-aggregator/internal/apperror"
+	"flight-aggregator/internal/config"
+	"flight-aggregator/internal/models"
+	"flight-aggregator/internal/repository"
+	"strings"
+	"testing"
+)
+
+type mockAPIKeyRepository struct {
+	keys map[string]models.APIKey
+}
+
+func newMockAPIKeyRepository() *mockAPIKeyRepository {
+	return &mockAPIKeyRepository{keys: make(map[string]models.APIKey)}
+}
+
+func (m *mockAPIKeyRepository) Lookup(ctx context.Context, rawKey string) (*models.APIKey, error) {
+	return m.Get(ctx, repository.APIKeyID(repository.HashAPIKey(rawKey)))
+}
+
+func (m *mockAPIKeyRepository) Get(ctx context.Context, id string) (*models.APIKey, error) {
+	apiKey, ok := m.keys[id]
+	if !ok {
+		return nil, repository.ErrAPIKeyNotFound
+	}
+	return &apiKey, nil
+}
+
+func (m *mockAPIKeyRepository) Save(ctx context.Context, apiKey *models.APIKey) error {
+	m.keys[apiKey.ID] = *apiKey
+	return nil
+}
+
+func (m *mockAPIKeyRepository) List(ctx context.Context) ([]models.APIKey, error) {
+	var apiKeys []models.APIKey
+	for _, apiKey := range m.keys {
+		apiKeys = append(apiKeys, apiKey)
+	}
+	return apiKeys, nil
+}
+
+func (m *mockAPIKeyRepository) Delete(ctx context.Context, id string) error {
+	delete(m.keys, id)
+	return nil
+}
+
+func TestAPIKeyUsecase_CreateAndRevokeKey(t *testing.T) {
+	repo := newMockAPIKeyRepository()
+	usecase := NewAPIKeyUsecase(repo, newMockTenantRepository(), config.DefaultPlans)
+
+	created, err := usecase.CreateKey(context.Background(), models.CreateAPIKeyRequest{Name: "Partner A", Plan: "partner"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.HasPrefix(created.Key, apiKeyPrefix) {
+		t.Errorf("Expected key with prefix %s, got %s", apiKeyPrefix, created.Key)
+	}
+
+	stored, err := repo.Lookup(context.Background(), created.Key)
+	if err != nil {
+		t.Fatalf("Expected created key to be stored, got %v", err)
+	}
+	if !stored.Active || stored.Plan != "partner" {
+		t.Errorf("Expected active partner key, got %+v", stored)
+	}
+
+	if err := usecase.RevokeKey(context.Background(), created.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	stored, _ = repo.Get(context.Background(), created.ID)
+	if stored.Active {
+		t.Error("Expected key to be revoked")
+	}
+}
+
+func TestAPIKeyUsecase_CreateKey_UnknownPlan(t *testing.T) {
+	usecase := NewAPIKeyUsecase(newMockAPIKeyRepository(), newMockTenantRepository(), config.DefaultPlans)
+
+	_, err := usecase.CreateKey(context.Background(), models.CreateAPIKeyRequest{Name: "Partner A", Plan: "platinum"})
+	if !errors.Is(err, apperror.ErrValidation) {
+		t.Errorf("Expected validation error, got %v", err)
+	}
+}
+
+func TestAPIKeyUsecase_RevokeKey_NotFound(t *testing.T) {
+	usecase := NewAPIKeyUsecase(newMockAPIKeyRepository(), newMockTenantRepository(), config.DefaultPlans)
+
+	err := usecase.RevokeKey(context.Background(), "missing")
+	if !errors.Is(err, apperror.ErrNotFound) {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+}
+
+func TestAPIKeyUsecase_CreateKey_UnknownTenant(t *testing.T) {
+	usecase := NewAPIKeyUsecase(newMockAPIKeyRepository(), newMockTenantRepository(), config.DefaultPlans)
+
+	_, err := usecase.CreateKey(context.Background(), models.CreateAPIKeyRequest{Name: "Partner A", Plan: "partner", TenantID: "missing"})
+	if !errors.Is(err, apperror.ErrValidation) {
+		t.Errorf("Expected validation error, got %v", err)
+	}
+}
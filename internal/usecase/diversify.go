@@ -0,0 +1,50 @@
+package usecase
+
+import "flight-aggregator/internal/models"
+
+// diversify reranks the first topN of sorted flights in place so they show every
+// airline and departure bucket they can, with at most maxPerAirline flights of one
+// airline. Each slot takes the first remaining flight in sorted order that brings a
+// new airline or bucket, or else the first one, so the order moves as little as
+// representation needs. Flights left out keep their order after the top.
+func (fu *flightUsecase) diversify(flights []models.Flight, topN, maxPerAirline int) {
+	if len(flights) < 2 || topN <= 0 {
+		return
+	}
+
+	remaining := append([]models.Flight(nil), flights...)
+	top := make([]models.Flight, 0, topN)
+	perAirline := make(map[string]int)
+	buckets := make(map[string]bool)
+	for len(top) < topN {
+		pick, firstAllowed := -1, -1
+		for i, flight := range remaining {
+			if perAirline[flight.Airline] >= maxPerAirline {
+				continue
+			}
+			if firstAllowed == -1 {
+				firstAllowed = i
+			}
+			if perAirline[flight.Airline] == 0 || !buckets[models.DepartureBucketOf(flight.DepartureTime)] {
+				pick = i
+				break
+			}
+		}
+		if pick == -1 {
+			pick = firstAllowed
+		}
+		// Every remaining airline is at its cap
+		if pick == -1 {
+			break
+		}
+
+		flight := remaining[pick]
+		top = append(top, flight)
+		perAirline[flight.Airline]++
+		buckets[models.DepartureBucketOf(flight.DepartureTime)] = true
+		remaining = append(remaining[:pick], remaining[pick+1:]...)
+	}
+
+	copy(flights, top)
+	copy(flights[len(top):], remaining)
+}
@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"context"
+	"flight-aggregator/internal/models"
+	"testing"
+	"time"
+)
+
+func TestFlightUsecase_Diversify(t *testing.T) {
+	usecase := NewFlightUsecase(&mockFlightService{}, nil, nil, nil).(*flightUsecase)
+	morning := time.Date(2026, 12, 15, 9, 0, 0, 0, time.UTC)
+	evening := time.Date(2026, 12, 15, 19, 0, 0, 0, time.UTC)
+	sorted := func() []models.Flight {
+		return []models.Flight{
+			{ID: "G1", Airline: "Garuda Indonesia", DepartureTime: morning},
+			{ID: "G2", Airline: "Garuda Indonesia", DepartureTime: morning},
+			{ID: "G3", Airline: "Garuda Indonesia", DepartureTime: morning},
+			{ID: "G4", Airline: "Garuda Indonesia", DepartureTime: morning},
+			{ID: "L1", Airline: "Lion Air", DepartureTime: morning},
+			{ID: "A1", Airline: "AirAsia", DepartureTime: evening},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		topN          int
+		maxPerAirline int
+		expected      string
+	}{
+		{"new airlines and buckets move up", 4, 2, "G1,L1,A1,G2,G3,G4"},
+		{"airline cap ends the top early", 6, 2, "G1,L1,A1,G2,G3,G4"},
+		{"only the top is reranked", 2, 2, "G1,L1,G2,G3,G4,A1"},
+		{"a cap of one", 3, 1, "G1,L1,A1,G2,G3,G4"},
+		{"a high cap keeps the score order after representation", 6, 10, "G1,L1,A1,G2,G3,G4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flights := sorted()
+			usecase.diversify(flights, tt.topN, tt.maxPerAirline)
+			if ids := flightIDs(flights); ids != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, ids)
+			}
+		})
+	}
+}
+
+func TestFlightUsecase_SearchFlights_Diversify(t *testing.T) {
+	usecase := NewFlightUsecase(newFixedFlightService(5), nil, nil, nil)
+	req := models.SearchRequest{Origin: "CGK", Destination: "DPS", DepartureDate: "2026-12-15", Passengers: 1, CabinClass: "economy"}
+	maxPerAirline := 2
+
+	result, err := usecase.SearchFlightsExpected(context.Background(), req, models.FilterOptions{SortBy: "price_asc", Diversify: true, MaxPerAirline: &maxPerAirline}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// Every fixed flight is Garuda, so only the cap applies and the order is kept
+	if len(result.Flights) != 5 || result.Flights[0].FlightNumber != "GA 100" || result.Flights[4].FlightNumber != "GA 104" {
+		t.Errorf("Expected all 5 flights in price order, got %+v", result.Flights)
+	}
+}
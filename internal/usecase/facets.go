@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"flight-aggregator/internal/models"
+	"sort"
+)
+
+// buildFacets counts the filter options of a set of flights. Airlines and aircraft
+// are ordered by count then name, stops ascending and departure times by bucket.
+// Options no flight has are left out.
+func buildFacets(flights []models.Flight, currency string) *models.Facets {
+	facets := &models.Facets{
+		Airlines:       []models.FacetCount{},
+		PriceRange:     models.PriceRange{Currency: currency},
+		DurationRange:  models.DurationRange{Unit: "minutes"},
+		Stops:          []models.StopsCount{},
+		DepartureTimes: []models.FacetCount{},
+		Aircraft:       []models.FacetCount{},
+	}
+	if len(flights) == 0 {
+		return facets
+	}
+
+	airlines := make(map[string]int)
+	aircraft := make(map[string]int)
+	stops := make(map[int]int)
+	buckets := make(map[string]int)
+	facets.PriceRange.Min, facets.PriceRange.Max = flights[0].Price, flights[0].Price
+	facets.DurationRange.Min, facets.DurationRange.Max = flights[0].Duration, flights[0].Duration
+	for _, flight := range flights {
+		airlines[flight.Airline]++
+		if flight.Aircraft != "" {
+			aircraft[flight.Aircraft]++
+		}
+		stops[flight.Stops]++
+		buckets[models.DepartureBucketOf(flight.DepartureTime)]++
+
+		facets.PriceRange.Min = min(facets.PriceRange.Min, flight.Price)
+		facets.PriceRange.Max = max(facets.PriceRange.Max, flight.Price)
+		facets.DurationRange.Min = min(facets.DurationRange.Min, flight.Duration)
+		facets.DurationRange.Max = max(facets.DurationRange.Max, flight.Duration)
+	}
+
+	facets.Airlines = countsByFrequency(airlines)
+	facets.Aircraft = countsByFrequency(aircraft)
+	for stopCount, count := range stops {
+		facets.Stops = append(facets.Stops, models.StopsCount{Stops: stopCount, Count: count})
+	}
+	sort.Slice(facets.Stops, func(i, j int) bool { return facets.Stops[i].Stops < facets.Stops[j].Stops })
+	for _, bucket := range models.DepartureBuckets {
+		if count := buckets[bucket.Name]; count > 0 {
+			facets.DepartureTimes = append(facets.DepartureTimes, models.FacetCount{Value: bucket.Name, Count: count})
+		}
+	}
+	return facets
+}
+
+func countsByFrequency(counts map[string]int) []models.FacetCount {
+	facetCounts := make([]models.FacetCount, 0, len(counts))
+	for value, count := range counts {
+		facetCounts = append(facetCounts, models.FacetCount{Value: value, Count: count})
+	}
+	sort.Slice(facetCounts, func(i, j int) bool {
+		if facetCounts[i].Count != facetCounts[j].Count {
+			return facetCounts[i].Count > facetCounts[j].Count
+		}
+		return facetCounts[i].Value < facetCounts[j].Value
+	})
+	return facetCounts
+}
+
+// sessionFacets describes the flights of a session the tenant can see, before the
+// request's own filters so the UI can still widen them
+func (fu *flightUsecase) sessionFacets(session *models.SearchSession, tenant *models.Tenant) *models.Facets {
+	flights := session.Flights
+	if tenant != nil && tenant.TravelPolicy != nil && tenant.TravelPolicy.HideOutOfPolicy {
+		flights, _ = fu.applyFilters(flights, models.FilterOptions{InPolicyOnly: true})
+	}
+	currency := defaultCurrency(tenant)
+	if len(flights) > 0 && flights[0].Currency != "" {
+		currency = flights[0].Currency
+	}
+	return buildFacets(flights, currency)
+}
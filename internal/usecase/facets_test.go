@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"context"
+	"flight-aggregator/internal/models"
+	"testing"
+	"time"
+)
+
+func TestBuildFacets(t *testing.T) {
+	day := time.Date(2026, 12, 15, 0, 0, 0, 0, time.UTC)
+	flights := []models.Flight{
+		{Airline: "Lion Air", Price: 900000, Duration: 110, Stops: 0, Aircraft: "Boeing 737-900ER", DepartureTime: day.Add(5 * time.Hour)},
+		{Airline: "Garuda Indonesia", Price: 1500000, Duration: 115, Stops: 0, Aircraft: "Boeing 737-800", DepartureTime: day.Add(9 * time.Hour)},
+		{Airline: "Lion Air", Price: 750000, Duration: 260, Stops: 1, Aircraft: "Boeing 737-900ER", DepartureTime: day.Add(23 * time.Hour)},
+		{Airline: "AirAsia", Price: 650000, Duration: 100, Stops: 0, DepartureTime: day.Add(9*time.Hour + 30*time.Minute)},
+	}
+
+	facets := buildFacets(flights, "IDR")
+
+	if len(facets.Airlines) != 3 || facets.Airlines[0] != (models.FacetCount{Value: "Lion Air", Count: 2}) || facets.Airlines[1].Value != "AirAsia" {
+		t.Errorf("Expected airlines by count then name, got %+v", facets.Airlines)
+	}
+	if facets.PriceRange != (models.PriceRange{Min: 650000, Max: 1500000, Currency: "IDR"}) {
+		t.Errorf("Expected the price range of the flights, got %+v", facets.PriceRange)
+	}
+	if facets.DurationRange.Min != 100 || facets.DurationRange.Max != 260 {
+		t.Errorf("Expected durations from 100 to 260, got %+v", facets.DurationRange)
+	}
+	if len(facets.Stops) != 2 || facets.Stops[0] != (models.StopsCount{Stops: 0, Count: 3}) || facets.Stops[1] != (models.StopsCount{Stops: 1, Count: 1}) {
+		t.Errorf("Expected 3 direct and 1 one-stop flight, got %+v", facets.Stops)
+	}
+	expectedBuckets := []models.FacetCount{{Value: models.BucketEarlyMorning, Count: 1}, {Value: models.BucketMorning, Count: 2}, {Value: models.BucketRedEye, Count: 1}}
+	if len(facets.DepartureTimes) != len(expectedBuckets) {
+		t.Fatalf("Expected %v, got %+v", expectedBuckets, facets.DepartureTimes)
+	}
+	for i, bucket := range expectedBuckets {
+		if facets.DepartureTimes[i] != bucket {
+			t.Errorf("Expected %v, got %+v", expectedBuckets, facets.DepartureTimes)
+		}
+	}
+	if len(facets.Aircraft) != 2 || facets.Aircraft[0] != (models.FacetCount{Value: "Boeing 737-900ER", Count: 2}) {
+		t.Errorf("Expected the aircraft with a known type, got %+v", facets.Aircraft)
+	}
+}
+
+func TestFlightUsecase_GetFilters_FromSearch(t *testing.T) {
+	usecase := NewFlightUsecase(newFixedFlightService(3), nil, nil, newMockSearchRepository())
+	req := models.SearchRequest{Origin: "CGK", Destination: "DPS", DepartureDate: "2026-12-15", Passengers: 1, CabinClass: "economy"}
+
+	maxPrice := 1000000.0
+	search, err := usecase.SearchFlightsExpected(context.Background(), req, models.FilterOptions{MaxPrice: &maxPrice}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if search.Facets == nil || search.Facets.PriceRange.Max != 1020000 || len(search.Flights) != 1 {
+		t.Fatalf("Expected facets of every flight found whatever the filters, got %+v", search.Facets)
+	}
+
+	filters, err := usecase.GetFilters(context.Background(), search.Metadata.SearchID, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(filters.Airlines) != 1 || filters.Airlines[0] != "Garuda Indonesia" || filters.MaxStops != 0 {
+		t.Errorf("Expected only the airlines and stops of the search, got %+v", filters)
+	}
+	if filters.PriceRange.Min != 1000000 || filters.DurationRange.Max != 120 || filters.Facets == nil {
+		t.Errorf("Expected the ranges of the search, got %+v %+v", filters.PriceRange, filters.DurationRange)
+	}
+}
@@ -2,77 +2,232 @@ package usecase
 
 import (
 	"context"
+	"flight-aggregator/internal/apperror"
 	"flight-aggregator/internal/config"
 	"flight-aggregator/internal/models"
+	"flight-aggregator/internal/providers"
+	"flight-aggregator/internal/repository"
 	"flight-aggregator/internal/service"
 	"flight-aggregator/internal/utils"
 	"fmt"
-	"sort"
+	"log"
 	"strings"
 	"time"
 )
 
 type FlightUsecase interface {
-	SearchFlightsExpected(ctx context.Context, req models.SearchRequest, filters models.FilterOptions) (*models.ExpectedSearchResponse, error)
-	GetFilters(ctx context.Context) (*models.FiltersResponse, error)
+	// tenant may be nil for requests outside any tenant
+	SearchFlightsExpected(ctx context.Context, req models.SearchRequest, filters models.FilterOptions, tenant *models.Tenant) (*models.ExpectedSearchResponse, error)
+	// GetSearch presents a stored search again with other filters, sorting or paging
+	GetSearch(ctx context.Context, id string, filters models.FilterOptions, tenant *models.Tenant) (*models.ExpectedSearchResponse, error)
+	// GetFilters returns the filter options of a stored search, or the catalog of
+	// every option when searchID is empty
+	GetFilters(ctx context.Context, searchID string, tenant *models.Tenant) (*models.FiltersResponse, error)
 }
 
+// Filters named in the debug output of a search
+const (
+	FilterPrice    = "price"
+	FilterStops    = "stops"
+	FilterDuration = "duration"
+	FilterAirline  = "airline"
+	FilterPolicy   = "policy"
+
+	FilterDepartureTime = "departure_time"
+	FilterArrivalTime   = "arrival_time"
+	FilterAircraft      = "aircraft"
+	FilterAmenities     = "amenities"
+	FilterBaggage       = "baggage"
+	FilterFareRules     = "fare_rules"
+	FilterDominated     = "dominated" // smart results only
+)
+
 type flightUsecase struct {
 	flightService service.FlightService
+	pricingEngine *PricingEngine
+	promos        repository.PromoRepository
+	searches      repository.SearchRepository
+	scorers       map[string]Scorer
 	dateUtil      *utils.DateUtil
 	currencyUtil  *utils.CurrencyUtil
 	config        *config.Config
 }
 
-func NewFlightUsecase(flightService service.FlightService) FlightUsecase {
+// NewFlightUsecase creates the flight usecase. A nil pricingEngine sells at the provider
+// fares, a nil promos store rejects every promo code and a nil searches store returns
+// every flight in one page.
+func NewFlightUsecase(flightService service.FlightService, pricingEngine *PricingEngine, promos repository.PromoRepository, searches repository.SearchRepository) FlightUsecase {
+	cfg := config.MustLoad()
 	return &flightUsecase{
 		flightService: flightService,
+		pricingEngine: pricingEngine,
+		promos:        promos,
+		searches:      searches,
+		scorers:       NewScorers(cfg),
 		dateUtil:      utils.NewDateUtil(),
 		currencyUtil:  utils.NewCurrencyUtil(),
-		config:        config.MustLoad(),
+		config:        cfg,
 	}
 }
 
-func (fu *flightUsecase) SearchFlightsExpected(ctx context.Context, req models.SearchRequest, filters models.FilterOptions) (*models.ExpectedSearchResponse, error) {
+func (fu *flightUsecase) SearchFlightsExpected(ctx context.Context, req models.SearchRequest, filters models.FilterOptions, tenant *models.Tenant) (*models.ExpectedSearchResponse, error) {
 	startTime := time.Now()
 	
 	if fu.flightService == nil {
-		return nil, fmt.Errorf("INTERNAL_ERROR: Flight service not initialized")
+		return nil, apperror.New(apperror.CodeInternal, "Flight service not initialized")
+	}
+	// Later pages come from the stored search, the providers are not queried again
+	if filters.Cursor != "" {
+		return fu.searchPage(ctx, "", filters, tenant)
+	}
+	// Reject bad ranking options before querying the providers
+	if _, _, err := fu.rankingFor(filters, tenant); err != nil {
+		return nil, err
+	}
+
+	session, err := fu.fetchFlights(ctx, req, tenant, startTime)
+	if err != nil {
+		return nil, err
+	}
+	fu.saveSession(ctx, session)
+
+	response, err := fu.presentFlights(session, filters, tenant, startTime)
+	if err != nil {
+		return nil, err
+	}
+	return fu.paginate(response, filters, 0), nil
+}
+
+// fetchFlights queries the providers and normalizes their flights into a session:
+// local times, selling prices, promo discounts and policy annotations, limited to
+// the searched route
+func (fu *flightUsecase) fetchFlights(ctx context.Context, req models.SearchRequest, tenant *models.Tenant, startTime time.Time) (*models.SearchSession, error) {
+	// Reject a bad promo code before querying the providers
+	promo, err := resolvePromo(ctx, fu.promos, req.PromoCode, startTime)
+	if err != nil {
+		return nil, err
 	}
 
-	flights, err := fu.flightService.GetAllFlights(ctx, req)
+	result, err := fu.flightService.GetAllFlights(ctx, req, tenant)
 	if err != nil {
 		return nil, err
 	}
+	flights := result.Flights
+	pricingContext := PricingContext{
+		Channel:    tenant.SalesChannel(),
+		CabinClass: req.CabinClass,
+	}
+	var policy *models.TravelPolicy
+	if tenant != nil {
+		pricingContext.TenantID = tenant.ID
+		policy = tenant.TravelPolicy
+	}
 
 	for i := range flights {
 		// Convert timezone
 		flights[i].DepartureTime = fu.dateUtil.ConvertToIndonesianTimezone(flights[i].DepartureTime, flights[i].Origin)
 		flights[i].ArrivalTime = fu.dateUtil.ConvertToIndonesianTimezone(flights[i].ArrivalTime, flights[i].Destination)
 		
+		// Prices are not converted, the tenant currency only labels flights the provider left without one
+		if flights[i].Currency == "" {
+			flights[i].Currency = defaultCurrency(tenant)
+		}
+
+		// Apply pricing rules, filters and ranking then work on the selling price
+		flights[i].NetFare = flights[i].Price
+		if fu.pricingEngine != nil {
+			flights[i].Price, flights[i].Adjustments = fu.pricingEngine.Price(flights[i], pricingContext)
+		}
+		if promo != nil && promoApplies(promo, flights[i]) {
+			discount := promoDiscount(promo, flights[i].Price)
+			flights[i].OriginalPrice = flights[i].Price
+			flights[i].Price -= discount
+			flights[i].Adjustments = append(flights[i].Adjustments, models.PriceAdjustment{
+				RuleID:      promo.Code,
+				Kind:        models.AdjustmentKindPromo,
+				Description: promo.Description,
+				Amount:      -discount,
+			})
+		}
+		if policy != nil {
+			flights[i].Policy = evaluatePolicy(policy, flights[i], req.CabinClass, startTime)
+		}
+
 		// Format currency
 		flights[i].PriceFormatted = fu.currencyUtil.FormatIDR(flights[i].Price)
-		
-		// Calculate best value
-		flights[i].BestValue = fu.calculateBestValue(flights[i])
-		
+	}
 
+	session := &models.SearchSession{
+		Request:   req,
+		CreatedAt: startTime.UTC(),
+		ExpiresAt: startTime.UTC().Add(fu.config.SearchTTL),
+		Providers: result.Providers,
+		Flights:   fu.applySearchCriteria(flights, req),
+	}
+	if tenant != nil {
+		session.TenantID = tenant.ID
 	}
+	if promo != nil {
+		session.Promo = fu.recordPromoUse(ctx, promo, session.Flights)
+	}
+	return session, nil
+}
 
-	// First filter by search criteria (origin/destination)
-	matchingFlights := fu.applySearchCriteria(flights, req)
-	
-	// Then apply additional filters
-	filteredFlights := fu.applyFilters(matchingFlights, filters)
-	fu.sortFlights(filteredFlights, filters.SortBy)
+// presentFlights filters, ranks and sorts the flights of a session for one view.
+// It never queries the providers, so a stored session can be presented again.
+func (fu *flightUsecase) presentFlights(session *models.SearchSession, filters models.FilterOptions, tenant *models.Tenant, startTime time.Time) (*models.ExpectedSearchResponse, error) {
+	scorer, scoring, err := fu.rankingFor(filters, tenant)
+	if err != nil {
+		return nil, err
+	}
+	// The tenant's policy can hide out-of-policy flights whatever the request asks
+	if tenant != nil && tenant.TravelPolicy != nil && tenant.TravelPolicy.HideOutOfPolicy {
+		filters.InPolicyOnly = true
+	}
+
+	// Work on a copy, ranking writes to the flights and the session may be presented again
+	flights := append([]models.Flight(nil), session.Flights...)
+	filteredFlights, excluded := fu.applyFilters(flights, filters)
+
+	// Score what the client will see, relative scorers depend on the result set
+	scores := fu.scorers[scorer].Score(filteredFlights, scoring)
+	for i := range filteredFlights {
+		filteredFlights[i].BestValue = scores[i].Total
+		if filters.Debug {
+			filteredFlights[i].ScoreBreakdown = &scores[i]
+		}
+	}
+	if filters.Mode == models.ResultModeSmart {
+		var dominated []models.ExcludedFlight
+		filteredFlights, dominated = fu.smartResults(filteredFlights, filters.PreferredDeparture)
+		excluded = append(excluded, dominated...)
+	}
+	fu.sortFlights(filteredFlights, filters.SortOrder())
+	if filters.Diversify {
+		maxPerAirline := fu.config.DiversifyAirlineCap
+		if filters.MaxPerAirline != nil {
+			maxPerAirline = *filters.MaxPerAirline
+		}
+		fu.diversify(filteredFlights, fu.config.DiversifyTopN, maxPerAirline)
+	}
+	if tenant != nil && tenant.MaxResults > 0 && len(filteredFlights) > tenant.MaxResults {
+		filteredFlights = filteredFlights[:tenant.MaxResults]
+	}
 
 	// Convert to expected format
 	expectedFlights := fu.convertToExpectedFormat(filteredFlights)
 	
 	// Calculate dynamic metadata
-	metadata := fu.calculateMetadata(flights, expectedFlights, startTime)
+	metadata := fu.calculateMetadata(session.Providers, expectedFlights, startTime)
+	metadata.Promo = session.Promo
+	if session.ID != "" {
+		metadata.SearchID = session.ID
+		expiresAt := session.ExpiresAt
+		metadata.ExpiresAt = &expiresAt
+	}
 
-	return &models.ExpectedSearchResponse{
+	req := session.Request
+	response := &models.ExpectedSearchResponse{
 		SearchCriteria: models.SearchCriteria{
 			Origin:        req.Origin,
 			Destination:   req.Destination,
@@ -82,33 +237,106 @@ func (fu *flightUsecase) SearchFlightsExpected(ctx context.Context, req models.S
 		},
 		Metadata: metadata,
 		Flights:  expectedFlights,
-	}, nil
+		Facets:   fu.sessionFacets(session, tenant),
+	}
+	if filters.Debug {
+		response.Debug = searchDebug(scorer, scoring, excluded)
+	}
+	return response, nil
+}
+
+// searchDebug reports the ranking settings and every flight the filters removed
+func searchDebug(scorer string, scoring ScoringOptions, excluded []models.ExcludedFlight) *models.SearchDebug {
+	debug := &models.SearchDebug{
+		Scorer:           scorer,
+		Weights:          scoring.Weights,
+		Excluded:         excluded,
+		ExcludedByFilter: make(map[string]int),
+	}
+	if debug.Excluded == nil {
+		debug.Excluded = []models.ExcludedFlight{}
+	}
+	for _, flight := range excluded {
+		debug.ExcludedByFilter[flight.Filter]++
+	}
+	return debug
 }
 
-func (fu *flightUsecase) calculateBestValue(flight models.Flight) float64 {
-	priceScore := 1.0 - (flight.Price / fu.config.MaxReasonablePrice)
-	if priceScore < 0 {
-		priceScore = 0
+// recordPromoUse counts the search against the promo's usage limit when the code
+// discounted at least one flight on the searched route
+func (fu *flightUsecase) recordPromoUse(ctx context.Context, promo *models.PromoCode, flights []models.Flight) *models.PromoSummary {
+	summary := &models.PromoSummary{Code: promo.Code}
+	for _, flight := range flights {
+		if flight.OriginalPrice > 0 {
+			summary.DiscountedFlights++
+		}
 	}
 
-	stopsScore := 1.0
-	if flight.Stops > 0 {
-		stopsScore = 0.7
+	if summary.DiscountedFlights > 0 {
+		if err := fu.promos.IncrementUsage(ctx, promo.Code); err != nil {
+			log.Printf("Failed to record use of promo code %s: %v", promo.Code, err)
+		}
 	}
+	return summary
+}
 
-	durationScore := 1.0 - (float64(flight.Duration) / float64(fu.config.MaxReasonableDuration))
-	if durationScore < 0 {
-		durationScore = 0
+// rankingFor picks the scorer and weights of a search, the request overrides the
+// tenant and the tenant overrides the defaults
+func (fu *flightUsecase) rankingFor(filters models.FilterOptions, tenant *models.Tenant) (string, ScoringOptions, error) {
+	name := DefaultScorer
+	if tenant != nil && tenant.Scorer != "" {
+		name = tenant.Scorer
+	}
+	if filters.Scorer != "" {
+		name = filters.Scorer
+	}
+	if _, ok := fu.scorers[name]; !ok {
+		return "", ScoringOptions{}, apperror.Validation([]apperror.FieldError{{
+			Field:   "scorer",
+			Rule:    "scorer",
+			Message: "must be one of " + strings.Join(ScorerNames, ", "),
+		}})
+	}
+
+	scoring := ScoringOptions{
+		Weights:            tenant.Weights(),
+		PreferredDeparture: filters.PreferredDeparture,
+	}
+	if filters.RankingWeights != nil {
+		scoring.Weights = *filters.RankingWeights
 	}
+	return name, scoring, nil
+}
 
-	return (priceScore * 0.5) + (stopsScore * 0.3) + (durationScore * 0.2)
+// defaultCurrency returns the tenant's currency, IDR outside a tenant
+func defaultCurrency(tenant *models.Tenant) string {
+	if tenant == nil || tenant.DefaultCurrency == "" {
+		return "IDR"
+	}
+	return tenant.DefaultCurrency
 }
 
-func (fu *flightUsecase) GetFilters(ctx context.Context) (*models.FiltersResponse, error) {
+func (fu *flightUsecase) GetFilters(ctx context.Context, searchID string, tenant *models.Tenant) (*models.FiltersResponse, error) {
+	if searchID != "" {
+		session, err := fu.loadSession(ctx, searchID, tenant)
+		if err != nil {
+			return nil, err
+		}
+		return filtersOf(fu.sessionFacets(session, tenant)), nil
+	}
+
+	// Each provider sells a single airline of the same name
+	var airlines []string
+	for _, airline := range providers.Names() {
+		if tenant.EnablesProvider(airline) {
+			airlines = append(airlines, airline)
+		}
+	}
+
 	return &models.FiltersResponse{
-		Airlines:     []string{"Garuda Indonesia", "Lion Air", "Batik Air", "AirAsia"},
-		CabinClasses: []string{"economy", "business", "first"},
-		SortOptions:  []string{"price_asc", "price_desc", "duration_asc", "duration_desc", "departure_time", "best_value"},
+		Airlines:     airlines,
+		CabinClasses: models.CabinClasses,
+		SortOptions:  models.SortOptions,
 		PriceRange: models.PriceRange{
 			Min:      500000,
 			Max:      fu.config.MaxReasonablePrice,
@@ -123,25 +351,79 @@ func (fu *flightUsecase) GetFilters(ctx context.Context) (*models.FiltersRespons
 	}, nil
 }
 
-func (fu *flightUsecase) applyFilters(flights []models.Flight, filters models.FilterOptions) []models.Flight {
+// filtersOf narrows the filter options to what the flights of a search offer
+func filtersOf(facets *models.Facets) *models.FiltersResponse {
+	airlines := make([]string, len(facets.Airlines))
+	for i, airline := range facets.Airlines {
+		airlines[i] = airline.Value
+	}
+	maxStops := 0
+	if len(facets.Stops) > 0 {
+		maxStops = facets.Stops[len(facets.Stops)-1].Stops
+	}
+	return &models.FiltersResponse{
+		Airlines:      airlines,
+		CabinClasses:  models.CabinClasses,
+		SortOptions:   models.SortOptions,
+		PriceRange:    facets.PriceRange,
+		DurationRange: facets.DurationRange,
+		MaxStops:      maxStops,
+		Facets:        facets,
+	}
+}
+
+// applyFilters returns the flights passing every filter and the ones that did not
+func (fu *flightUsecase) applyFilters(flights []models.Flight, filters models.FilterOptions) ([]models.Flight, []models.ExcludedFlight) {
 	if flights == nil {
-		return []models.Flight{}
+		return []models.Flight{}, nil
 	}
 
 	var filtered []models.Flight
+	var excluded []models.ExcludedFlight
 	for _, flight := range flights {
-		if fu.passesAllFilters(flight, filters) {
+		failed := fu.failedFilter(flight, filters)
+		if failed == "" {
 			filtered = append(filtered, flight)
+			continue
 		}
+		excluded = append(excluded, models.ExcludedFlight{
+			ID:       flight.ID,
+			Provider: flight.Provider,
+			Airline:  flight.Airline,
+			Price:    flight.Price,
+			Filter:   failed,
+		})
 	}
-	return filtered
+	return filtered, excluded
 }
 
-func (fu *flightUsecase) passesAllFilters(flight models.Flight, filters models.FilterOptions) bool {
-	return fu.passesPriceFilter(flight, filters) &&
-		fu.passesStopsFilter(flight, filters) &&
-		fu.passesDurationFilter(flight, filters) &&
-		fu.passesAirlineFilter(flight, filters)
+// failedFilter names the first filter the flight fails, empty when it passes them all
+func (fu *flightUsecase) failedFilter(flight models.Flight, filters models.FilterOptions) string {
+	switch {
+	case !fu.passesPriceFilter(flight, filters):
+		return FilterPrice
+	case !fu.passesStopsFilter(flight, filters):
+		return FilterStops
+	case !fu.passesDurationFilter(flight, filters):
+		return FilterDuration
+	case !fu.passesAirlineFilter(flight, filters):
+		return FilterAirline
+	case !fu.passesDepartureTimeFilter(flight, filters):
+		return FilterDepartureTime
+	case !fu.passesArrivalTimeFilter(flight, filters):
+		return FilterArrivalTime
+	case !fu.passesAircraftFilter(flight, filters):
+		return FilterAircraft
+	case !fu.passesAmenitiesFilter(flight, filters):
+		return FilterAmenities
+	case !fu.passesBaggageFilter(flight, filters):
+		return FilterBaggage
+	case !fu.passesFareRulesFilter(flight, filters):
+		return FilterFareRules
+	case !fu.passesPolicyFilter(flight, filters):
+		return FilterPolicy
+	}
+	return ""
 }
 
 func (fu *flightUsecase) applySearchCriteria(flights []models.Flight, req models.SearchRequest) []models.Flight {
@@ -190,37 +472,98 @@ func (fu *flightUsecase) passesAirlineFilter(flight models.Flight, filters model
 	return false
 }
 
-func (fu *flightUsecase) sortFlights(flights []models.Flight, sortBy string) {
-	if flights == nil || len(flights) == 0 {
-		return
+// Flight times are in the local time of their airport once converted, so time of
+// day filters read them as they are
+func (fu *flightUsecase) passesDepartureTimeFilter(flight models.Flight, filters models.FilterOptions) bool {
+	return passesTimeOfDay(flight.DepartureTime, filters.DepartureWindow, filters.DepartureBuckets)
+}
+
+func (fu *flightUsecase) passesArrivalTimeFilter(flight models.Flight, filters models.FilterOptions) bool {
+	if !passesTimeOfDay(flight.ArrivalTime, filters.ArrivalWindow, filters.ArrivalBuckets) {
+		return false
 	}
+	if filters.ArriveBy == "" {
+		return true
+	}
+	// An arrival on a later day than the departure misses the deadline whatever its time
+	depYear, depMonth, depDay := flight.DepartureTime.Date()
+	arrYear, arrMonth, arrDay := flight.ArrivalTime.Date()
+	if arrYear != depYear || arrMonth != depMonth || arrDay != depDay {
+		return false
+	}
+	return models.TimeWindow{From: "00:00", To: filters.ArriveBy}.Contains(flight.ArrivalTime)
+}
 
-	switch sortBy {
-	case "price_asc":
-		sort.Slice(flights, func(i, j int) bool {
-			return flights[i].Price < flights[j].Price
-		})
-	case "price_desc":
-		sort.Slice(flights, func(i, j int) bool {
-			return flights[i].Price > flights[j].Price
-		})
-	case "duration_asc":
-		sort.Slice(flights, func(i, j int) bool {
-			return flights[i].Duration < flights[j].Duration
-		})
-	case "duration_desc":
-		sort.Slice(flights, func(i, j int) bool {
-			return flights[i].Duration > flights[j].Duration
-		})
-	case "departure_time":
-		sort.Slice(flights, func(i, j int) bool {
-			return flights[i].DepartureTime.Before(flights[j].DepartureTime)
-		})
-	default:
-		sort.Slice(flights, func(i, j int) bool {
-			return flights[i].BestValue > flights[j].BestValue
-		})
+func passesTimeOfDay(t time.Time, window string, buckets []string) bool {
+	if window != "" {
+		if w, ok := models.ParseTimeWindow(window); ok && !w.Contains(t) {
+			return false
+		}
+	}
+	if len(buckets) == 0 {
+		return true
+	}
+	bucket := models.DepartureBucketOf(t)
+	for _, name := range buckets {
+		if name == bucket {
+			return true
+		}
+	}
+	return false
+}
+
+func (fu *flightUsecase) passesAircraftFilter(flight models.Flight, filters models.FilterOptions) bool {
+	if filters.AircraftBody != "" && models.AircraftBody(flight.Aircraft) != filters.AircraftBody {
+		return false
+	}
+	if len(filters.AircraftTypes) == 0 {
+		return true
 	}
+	aircraft := strings.ToUpper(flight.Aircraft)
+	for _, aircraftType := range filters.AircraftTypes {
+		if aircraftType != "" && strings.Contains(aircraft, strings.ToUpper(aircraftType)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (fu *flightUsecase) passesAmenitiesFilter(flight models.Flight, filters models.FilterOptions) bool {
+	for _, required := range filters.Amenities {
+		found := false
+		for _, amenity := range flight.Amenities {
+			if amenity == required {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (fu *flightUsecase) passesBaggageFilter(flight models.Flight, filters models.FilterOptions) bool {
+	if filters.CheckedBaggageIncluded && !flight.Baggage.IncludesChecked() {
+		return false
+	}
+	return filters.MinCheckedBaggageKg == nil || flight.Baggage.CheckedAllowanceKg() >= *filters.MinCheckedBaggageKg
+}
+
+// passesFareRulesFilter only keeps flights known to be flexible, a fare whose rules
+// the provider does not report may be neither
+func (fu *flightUsecase) passesFareRulesFilter(flight models.Flight, filters models.FilterOptions) bool {
+	if filters.Refundable && (flight.Refundable == nil || !*flight.Refundable) {
+		return false
+	}
+	return !filters.Changeable || (flight.Changeable != nil && *flight.Changeable)
+}
+
+// passesPolicyFilter only hides flights annotated as out of policy, flights
+// searched without a travel policy always pass
+func (fu *flightUsecase) passesPolicyFilter(flight models.Flight, filters models.FilterOptions) bool {
+	return !filters.InPolicyOnly || flight.Policy == nil || flight.Policy.InPolicy
 }
 
 func (fu *flightUsecase) convertToExpectedFormat(flights []models.Flight) []models.ExpectedFlight {
@@ -236,6 +579,11 @@ func (fu *flightUsecase) convertToExpectedFormat(flights []models.Flight) []mode
 			aircraft = &flight.Aircraft
 		}
 		
+		var originalAmount *float64
+		if flight.OriginalPrice > 0 {
+			originalAmount = &flight.OriginalPrice
+		}
+
 		expectedFlight := models.ExpectedFlight{
 			ID:           flight.ID + "_" + flight.Provider,
 			Provider:     flight.Provider,
@@ -262,17 +610,23 @@ func (fu *flightUsecase) convertToExpectedFormat(flights []models.Flight) []mode
 			},
 			Stops:          flight.Stops,
 			Price: models.Price{
-				Amount:   flight.Price,
-				Currency: flight.Currency,
+				Amount:         flight.Price,
+				Currency:       flight.Currency,
+				NetFare:        flight.NetFare,
+				OriginalAmount: originalAmount,
+				Adjustments:    flight.Adjustments,
 			},
-			AvailableSeats: 88, // Default value as shown in expected
+			AvailableSeats: flight.AvailableSeats,
 			CabinClass:     "economy",
 			Aircraft:       aircraft,
-			Amenities:      []string{},
-			Baggage: models.Baggage{
-				CarryOn: "Cabin baggage only",
-				Checked: "Additional fee",
-			},
+			Amenities:      append([]string{}, flight.Amenities...),
+			Baggage:        formatBaggage(flight.Baggage),
+			Refundable:     flight.Refundable,
+			Changeable:     flight.Changeable,
+			Policy:         flight.Policy,
+			Layovers:       flight.Layovers,
+			ScoreBreakdown: flight.ScoreBreakdown,
+			SmartLabels:    flight.SmartLabels,
 		}
 		
 		expectedFlights = append(expectedFlights, expectedFlight)
@@ -281,6 +635,31 @@ func (fu *flightUsecase) convertToExpectedFormat(flights []models.Flight) []mode
 	return expectedFlights
 }
 
+// formatBaggage describes an allowance, baggage without an allowance is charged for
+func formatBaggage(baggage models.BaggageAllowance) models.Baggage {
+	formatted := models.Baggage{CarryOn: "Cabin baggage only", Checked: "Additional fee"}
+	switch {
+	case baggage.CabinKg > 0:
+		formatted.CarryOn = fmt.Sprintf("%d kg", baggage.CabinKg)
+	case baggage.CabinPieces > 0:
+		formatted.CarryOn = formatPieces(baggage.CabinPieces)
+	}
+	switch {
+	case baggage.CheckedKg > 0:
+		formatted.Checked = fmt.Sprintf("%d kg", baggage.CheckedKg)
+	case baggage.CheckedPieces > 0:
+		formatted.Checked = formatPieces(baggage.CheckedPieces)
+	}
+	return formatted
+}
+
+func formatPieces(pieces int) string {
+	if pieces == 1 {
+		return "1 piece"
+	}
+	return fmt.Sprintf("%d pieces", pieces)
+}
+
 func (fu *flightUsecase) extractAirlineCode(airlineName string) string {
 	switch airlineName {
 	case "AirAsia":
@@ -315,8 +694,8 @@ func (fu *flightUsecase) formatDuration(minutes int) string {
 	return fmt.Sprintf("%dh %dm", hours, mins)
 }
 
-func (fu *flightUsecase) calculateMetadata(allFlights []models.Flight, filteredFlights []models.ExpectedFlight, startTime time.Time) models.Metadata {
-	providerStats := fu.calculateProviderStats(allFlights)
+func (fu *flightUsecase) calculateMetadata(providerStatuses []models.ProviderStatus, filteredFlights []models.ExpectedFlight, startTime time.Time) models.Metadata {
+	providerStats := fu.calculateProviderStats(providerStatuses)
 	searchTimeMs := int(time.Since(startTime).Milliseconds())
 	
 	return models.Metadata{
@@ -324,8 +703,10 @@ func (fu *flightUsecase) calculateMetadata(allFlights []models.Flight, filteredF
 		ProvidersQueried:   providerStats.queried,
 		ProvidersSucceeded: providerStats.succeeded,
 		ProvidersFailed:    providerStats.failed,
+		ProvidersThrottled: providerStats.throttled,
 		SearchTimeMs:       searchTimeMs,
 		CacheHit:          false,
+		Providers:          providerStatuses,
 	}
 }
 
@@ -333,21 +714,20 @@ type providerStatistics struct {
 	queried   int
 	succeeded int
 	failed    int
+	throttled int
 }
 
-func (fu *flightUsecase) calculateProviderStats(flights []models.Flight) providerStatistics {
-	providers := make(map[string]bool)
-	for _, flight := range flights {
-		providers[flight.Provider] = true
-	}
-	
-	queried := 4 // Total known providers
-	succeeded := len(providers)
-	failed := queried - succeeded
-	
-	return providerStatistics{
-		queried:   queried,
-		succeeded: succeeded,
-		failed:    failed,
+func (fu *flightUsecase) calculateProviderStats(providerStatuses []models.ProviderStatus) providerStatistics {
+	stats := providerStatistics{queried: len(providerStatuses)}
+	for _, status := range providerStatuses {
+		switch status.Status {
+		case models.ProviderStatusSuccess:
+			stats.succeeded++
+		case models.ProviderStatusThrottled:
+			stats.throttled++
+		default:
+			stats.failed++
+		}
 	}
-}
\ No newline at end of file
+	return stats
+}
@@ -3,14 +3,16 @@ package usecase
 import (
 	"context"
 	"flight-aggregator/internal/models"
+	"flight-aggregator/internal/service"
+	"strings"
 	"testing"
 	"time"
 )
 
 type mockFlightService struct{}
 
-func (m *mockFlightService) GetAllFlights(ctx context.Context, req models.SearchRequest) ([]models.Flight, error) {
-	return []models.Flight{
+func (m *mockFlightService) GetAllFlights(ctx context.Context, req models.SearchRequest, tenant *models.Tenant) (*service.SearchResult, error) {
+	return &service.SearchResult{Flights: []models.Flight{
 		{
 			ID:            "GA400",
 			Airline:       "Garuda Indonesia",
@@ -26,12 +28,16 @@ func (m *mockFlightService) GetAllFlights(ctx context.Context, req models.Search
 			Aircraft:      "Boeing 737",
 			Provider:      "Garuda Indonesia",
 		},
-	}, nil
+	}, Providers: []models.ProviderStatus{
+		{Name: "Garuda Indonesia", Status: models.ProviderStatusSuccess, Attempts: 1, FlightCount: 1},
+		{Name: "Lion Air", Status: models.ProviderStatusSuccess, Attempts: 1, FlightCount: 0},
+		{Name: "AirAsia", Status: models.ProviderStatusFailed, Attempts: 3, ErrorCode: "PROVIDER_ERROR"},
+	}}, nil
 }
 
 func TestFlightUsecase_SearchFlights(t *testing.T) {
 	service := &mockFlightService{}
-	usecase := NewFlightUsecase(service)
+	usecase := NewFlightUsecase(service, nil, nil, nil)
 
 	req := models.SearchRequest{
 		Origin:        "CGK",
@@ -45,7 +51,7 @@ func TestFlightUsecase_SearchFlights(t *testing.T) {
 		SortBy: "best_value",
 	}
 
-	result, err := usecase.SearchFlightsExpected(context.Background(), req, filters)
+	result, err := usecase.SearchFlightsExpected(context.Background(), req, filters, nil)
 
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
@@ -62,13 +68,40 @@ func TestFlightUsecase_SearchFlights(t *testing.T) {
 	if result.Metadata.TotalResults != 1 {
 		t.Errorf("Expected 1 total result, got %d", result.Metadata.TotalResults)
 	}
+
+	// A provider that succeeds with zero flights still counts as succeeded
+	if result.Metadata.ProvidersQueried != 3 || result.Metadata.ProvidersSucceeded != 2 || result.Metadata.ProvidersFailed != 1 {
+		t.Errorf("Expected 3 queried, 2 succeeded, 1 failed, got %+v", result.Metadata)
+	}
+
+	if len(result.Metadata.Providers) != 3 {
+		t.Errorf("Expected 3 provider statuses, got %d", len(result.Metadata.Providers))
+	}
+}
+
+func TestFlightUsecase_CalculateProviderStats(t *testing.T) {
+	usecase := NewFlightUsecase(&mockFlightService{}, nil, nil, nil).(*flightUsecase)
+	statuses := []models.ProviderStatus{
+		{Name: "Garuda Indonesia", Status: models.ProviderStatusSuccess},
+		{Name: "Lion Air", Status: models.ProviderStatusThrottled},
+		{Name: "Batik Air", Status: models.ProviderStatusTimeout},
+		{Name: "AirAsia", Status: models.ProviderStatusFailed},
+	}
+
+	stats := usecase.calculateProviderStats(statuses)
+	if stats.succeeded != 1 || stats.throttled != 1 || stats.failed != 2 {
+		t.Errorf("Expected 1 succeeded, 1 throttled and 2 failed, got %+v", stats)
+	}
+	if stats.succeeded+stats.failed+stats.throttled != stats.queried {
+		t.Errorf("Expected every queried provider counted once, got %+v", stats)
+	}
 }
 
 func TestFlightUsecase_GetFilters(t *testing.T) {
 	service := &mockFlightService{}
-	usecase := NewFlightUsecase(service)
+	usecase := NewFlightUsecase(service, nil, nil, nil)
 
-	result, err := usecase.GetFilters(context.Background())
+	result, err := usecase.GetFilters(context.Background(), "", nil)
 
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
@@ -85,4 +118,185 @@ func TestFlightUsecase_GetFilters(t *testing.T) {
 	if len(result.SortOptions) == 0 {
 		t.Error("Expected sort options to be populated")
 	}
-}
\ No newline at end of file
+}
+
+func TestFlightUsecase_TenantSettings(t *testing.T) {
+	usecase := NewFlightUsecase(&mockFlightService{}, nil, nil, nil).(*flightUsecase)
+	tenant := &models.Tenant{
+		ID:               "acme",
+		EnabledProviders: []string{"Garuda Indonesia", "AirAsia"},
+		RankingWeights:   &models.RankingWeights{Price: 0, Stops: 1, Duration: 0},
+	}
+
+	filters, err := usecase.GetFilters(context.Background(), "", tenant)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(filters.Airlines) != 2 || filters.Airlines[0] != "Garuda Indonesia" || filters.Airlines[1] != "AirAsia" {
+		t.Errorf("Expected only the tenant's airlines, got %v", filters.Airlines)
+	}
+
+	// Only stops count, so a direct flight scores the full weight whatever its price
+	scores := usecase.scorers[ScorerAbsolute].Score([]models.Flight{{Price: 4000000, Duration: 500}}, ScoringOptions{Weights: tenant.Weights()})
+	score := scores[0].Total
+	if score != 1 {
+		t.Errorf("Expected best value 1 with stops-only weights, got %v", score)
+	}
+}
+
+func TestFlightUsecase_SearchFlights_Pricing(t *testing.T) {
+	engine, _ := NewPricingEngine(staticRules(models.PricingRule{
+		ID: "b2b-fee", Kind: models.PricingKindFee, Mode: models.PricingModeFixed, Value: 50000,
+		Stackable: true, Match: models.RuleMatch{Channels: []string{models.ChannelB2B}},
+	}))
+	usecase := NewFlightUsecase(&mockFlightService{}, engine, nil, nil)
+	req := models.SearchRequest{Origin: "CGK", Destination: "DPS", DepartureDate: "2025-12-15", Passengers: 1, CabinClass: "economy"}
+
+	result, err := usecase.SearchFlightsExpected(context.Background(), req, models.FilterOptions{}, &models.Tenant{ID: "acme", Channel: models.ChannelB2B})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	price := result.Flights[0].Price
+	if price.NetFare != 1250000 || price.Amount != 1300000 {
+		t.Errorf("Expected net fare 1250000 sold at 1300000, got %+v", price)
+	}
+	if len(price.Adjustments) != 1 || price.Adjustments[0].RuleID != "b2b-fee" {
+		t.Errorf("Expected the b2b fee adjustment, got %+v", price.Adjustments)
+	}
+}
+
+func TestFlightUsecase_SearchFlights_Debug(t *testing.T) {
+	usecase := NewFlightUsecase(&mockFlightService{}, nil, nil, nil)
+	req := models.SearchRequest{Origin: "CGK", Destination: "DPS", DepartureDate: "2025-12-15", Passengers: 1, CabinClass: "economy"}
+	maxPrice := 1000000.0
+
+	result, err := usecase.SearchFlightsExpected(context.Background(), req, models.FilterOptions{}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Debug != nil || result.Flights[0].ScoreBreakdown != nil {
+		t.Error("Expected no debug output unless asked for")
+	}
+
+	result, err = usecase.SearchFlightsExpected(context.Background(), req, models.FilterOptions{Debug: true}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	breakdown := result.Flights[0].ScoreBreakdown
+	if breakdown == nil || len(breakdown.Components) != 3 || breakdown.Components[0].Name != ComponentPrice {
+		t.Fatalf("Expected the price, stops and duration components, got %+v", breakdown)
+	}
+	if breakdown.Components[0].Weight != models.DefaultRankingWeights.Price {
+		t.Errorf("Expected the default price weight, got %v", breakdown.Components[0].Weight)
+	}
+	if result.Debug == nil || result.Debug.Scorer != DefaultScorer {
+		t.Errorf("Expected the default scorer reported, got %+v", result.Debug)
+	}
+
+	result, err = usecase.SearchFlightsExpected(context.Background(), req, models.FilterOptions{MaxPrice: &maxPrice, Debug: true}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(result.Flights) != 0 || len(result.Debug.Excluded) != 1 || result.Debug.Excluded[0].ID != "GA400" {
+		t.Fatalf("Expected GA400 reported as excluded, got %+v", result.Debug)
+	}
+	if result.Debug.ExcludedByFilter[FilterPrice] != 1 {
+		t.Errorf("Expected one flight excluded by the price filter, got %v", result.Debug.ExcludedByFilter)
+	}
+}
+
+// flightIDs lists the IDs of flights in order, comma separated
+func flightIDs(flights []models.Flight) string {
+	ids := make([]string, len(flights))
+	for i, flight := range flights {
+		ids[i] = flight.ID
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestFlightUsecase_TimeOfDayFilters(t *testing.T) {
+	usecase := NewFlightUsecase(&mockFlightService{}, nil, nil, nil).(*flightUsecase)
+	utc := func(day, hour, minute int) time.Time { return time.Date(2026, 12, day, hour, minute, 0, 0, time.UTC) }
+	// CGK is in WIB (UTC+7) and DPS in WITA (UTC+8)
+	flights := []models.Flight{
+		{ID: "morning", Origin: "CGK", Destination: "DPS", DepartureTime: utc(14, 23, 30), ArrivalTime: utc(15, 1, 20)},  // 06:30 WIB, 09:20 WITA
+		{ID: "evening", Origin: "CGK", Destination: "DPS", DepartureTime: utc(15, 11, 0), ArrivalTime: utc(15, 13, 0)},    // 18:00 WIB, 21:00 WITA
+		{ID: "overnight", Origin: "CGK", Destination: "DPS", DepartureTime: utc(15, 15, 30), ArrivalTime: utc(15, 17, 30)}, // 22:30 WIB, 01:30 WITA the next day
+	}
+	for i := range flights {
+		flights[i].DepartureTime = usecase.dateUtil.ConvertToIndonesianTimezone(flights[i].DepartureTime, flights[i].Origin)
+		flights[i].ArrivalTime = usecase.dateUtil.ConvertToIndonesianTimezone(flights[i].ArrivalTime, flights[i].Destination)
+	}
+
+	tests := []struct {
+		name     string
+		filters  models.FilterOptions
+		expected []string
+	}{
+		{"departure window in origin time", models.FilterOptions{DepartureWindow: "06:00-12:00"}, []string{"morning"}},
+		{"departure buckets", models.FilterOptions{DepartureBuckets: []string{models.BucketEvening, models.BucketRedEye}}, []string{"evening", "overnight"}},
+		{"arrival window past midnight", models.FilterOptions{ArrivalWindow: "21:00-02:00"}, []string{"evening", "overnight"}},
+		{"window and bucket together", models.FilterOptions{DepartureWindow: "00:00-12:00", DepartureBuckets: []string{models.BucketMorning}}, nil},
+		{"arrive by excludes next day arrivals", models.FilterOptions{ArriveBy: "10:00"}, []string{"morning"}},
+		{"arrive by in destination time", models.FilterOptions{ArriveBy: "21:00"}, []string{"morning", "evening"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, excluded := usecase.applyFilters(flights, tt.filters)
+			var ids []string
+			for _, flight := range kept {
+				ids = append(ids, flight.ID)
+			}
+			if len(ids) != len(tt.expected) {
+				t.Fatalf("Expected %v, got %v", tt.expected, ids)
+			}
+			for i := range ids {
+				if ids[i] != tt.expected[i] {
+					t.Errorf("Expected %v, got %v", tt.expected, ids)
+				}
+			}
+			for _, flight := range excluded {
+				if flight.Filter != FilterDepartureTime && flight.Filter != FilterArrivalTime {
+					t.Errorf("Expected %s excluded by a time filter, got %s", flight.ID, flight.Filter)
+				}
+			}
+		})
+	}
+}
+
+func TestFlightUsecase_FareAttributeFilters(t *testing.T) {
+	usecase := NewFlightUsecase(&mockFlightService{}, nil, nil, nil).(*flightUsecase)
+	refundable := true
+	flights := []models.Flight{
+		{ID: "widebody", Aircraft: "Airbus A330-300", Amenities: []string{models.AmenityWifi, models.AmenityMeal, models.AmenityPower}, Baggage: models.BaggageAllowance{CheckedPieces: 2}, Refundable: &refundable},
+		{ID: "full-service", Aircraft: "Boeing 737-800", Amenities: []string{models.AmenityWifi, models.AmenityMeal}, Baggage: models.BaggageAllowance{CheckedKg: 20}},
+		{ID: "low-cost", Aircraft: "Airbus A320"},
+	}
+	minBaggage := 30
+
+	tests := []struct {
+		name     string
+		filters  models.FilterOptions
+		expected []string
+	}{
+		{"wide body", models.FilterOptions{AircraftBody: models.AircraftWideBody}, []string{"widebody"}},
+		{"aircraft types", models.FilterOptions{AircraftTypes: []string{"a320", "737"}}, []string{"full-service", "low-cost"}},
+		{"every amenity", models.FilterOptions{Amenities: []string{models.AmenityWifi, models.AmenityMeal}}, []string{"widebody", "full-service"}},
+		{"power", models.FilterOptions{Amenities: []string{models.AmenityPower}}, []string{"widebody"}},
+		{"checked bag included", models.FilterOptions{CheckedBaggageIncluded: true}, []string{"widebody", "full-service"}},
+		{"checked pieces count as kg", models.FilterOptions{MinCheckedBaggageKg: &minBaggage}, []string{"widebody"}},
+		{"refundable excludes unknown fare rules", models.FilterOptions{Refundable: true}, []string{"widebody"}},
+		{"changeable", models.FilterOptions{Changeable: true}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, _ := usecase.applyFilters(flights, tt.filters)
+			if ids := flightIDs(kept); ids != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected %v, got %s", tt.expected, ids)
+			}
+		})
+	}
+}
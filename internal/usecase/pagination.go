@@ -0,0 +1,189 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"flight-aggregator/internal/apperror"
+	"flight-aggregator/internal/models"
+	"flight-aggregator/internal/repository"
+	"log"
+	"time"
+)
+
+// pageCursor locates a page of a stored search and the view it was cut from, so a
+// cursor alone reproduces the same filters and order. It is handed out base64
+// encoded and clients treat it as opaque.
+type pageCursor struct {
+	SearchID string               `json:"s"`
+	Offset   int                  `json:"o"`
+	Limit    int                  `json:"l"`
+	View     models.FilterOptions `json:"v"`
+}
+
+func encodeCursor(cursor pageCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(raw string) (pageCursor, error) {
+	var cursor pageCursor
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err == nil {
+		err = json.Unmarshal(data, &cursor)
+	}
+	if err != nil || cursor.SearchID == "" || cursor.Offset < 0 || cursor.Limit < 1 || cursor.Limit > models.MaxPageSize {
+		return pageCursor{}, invalidCursor("is not a valid cursor")
+	}
+	// Cursors are not signed, so their view is checked like filters sent in the clear
+	if len(cursor.View.FieldErrors()) > 0 {
+		return pageCursor{}, invalidCursor("carries invalid filters")
+	}
+	return cursor, nil
+}
+
+func invalidCursor(message string) error {
+	return apperror.Validation([]apperror.FieldError{{Field: "cursor", Rule: "cursor", Message: message}})
+}
+
+func newSearchID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// saveSession stores a search so it can be presented again. Without a store, or
+// when storing fails, the session keeps no ID and its pages come without cursors.
+func (fu *flightUsecase) saveSession(ctx context.Context, session *models.SearchSession) {
+	if fu.searches == nil {
+		return
+	}
+	id, err := newSearchID()
+	if err != nil {
+		log.Printf("Failed to generate search id, the search is not stored: %v", err)
+		return
+	}
+	session.ID = id
+	if err := fu.searches.Save(ctx, session); err != nil {
+		log.Printf("Failed to store search %s: %v", id, err)
+		session.ID = ""
+	}
+}
+
+// loadSession reads a stored search. A search of another tenant is reported as
+// not found so its ID gives nothing away.
+func (fu *flightUsecase) loadSession(ctx context.Context, id string, tenant *models.Tenant) (*models.SearchSession, error) {
+	if fu.searches == nil {
+		return nil, apperror.Newf(apperror.CodeNotFound, "Search %s not found", id)
+	}
+	session, err := fu.searches.Get(ctx, id)
+	if err == repository.ErrSearchNotFound {
+		return nil, apperror.Newf(apperror.CodeNotFound, "Search %s not found or expired, search again", id)
+	}
+	if err != nil {
+		return nil, apperror.Wrap(apperror.CodeService, err, "Failed to load search")
+	}
+	tenantID := ""
+	if tenant != nil {
+		tenantID = tenant.ID
+	}
+	if session.TenantID != tenantID {
+		return nil, apperror.Newf(apperror.CodeNotFound, "Search %s not found or expired, search again", id)
+	}
+	return session, nil
+}
+
+// GetSearch presents a stored search with the given filters, sorting and paging
+// without querying the providers
+func (fu *flightUsecase) GetSearch(ctx context.Context, id string, filters models.FilterOptions, tenant *models.Tenant) (*models.ExpectedSearchResponse, error) {
+	if filters.Cursor != "" {
+		return fu.searchPage(ctx, id, filters, tenant)
+	}
+	session, err := fu.loadSession(ctx, id, tenant)
+	if err != nil {
+		return nil, err
+	}
+	response, err := fu.presentStored(session, filters, tenant)
+	if err != nil {
+		return nil, err
+	}
+	return fu.paginate(response, filters, 0), nil
+}
+
+// searchPage reads a page of a stored search in the view its cursor was cut from.
+// A limit overrides the page size of the cursor and a non-empty searchID must be
+// the search the cursor belongs to.
+func (fu *flightUsecase) searchPage(ctx context.Context, searchID string, filters models.FilterOptions, tenant *models.Tenant) (*models.ExpectedSearchResponse, error) {
+	cursor, err := decodeCursor(filters.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	if searchID != "" && cursor.SearchID != searchID {
+		return nil, invalidCursor("belongs to another search")
+	}
+	view := cursor.View
+	view.Limit = cursor.Limit
+	if filters.Limit > 0 {
+		view.Limit = filters.Limit
+	}
+	view.Debug = filters.Debug
+
+	session, err := fu.loadSession(ctx, cursor.SearchID, tenant)
+	if err != nil {
+		return nil, err
+	}
+	response, err := fu.presentStored(session, view, tenant)
+	if err != nil {
+		return nil, err
+	}
+	if cursor.Offset > len(response.Flights) {
+		return nil, invalidCursor("is past the last page")
+	}
+	return fu.paginate(response, view, cursor.Offset), nil
+}
+
+func (fu *flightUsecase) presentStored(session *models.SearchSession, filters models.FilterOptions, tenant *models.Tenant) (*models.ExpectedSearchResponse, error) {
+	response, err := fu.presentFlights(session, filters, tenant, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	// Every provider answer is replayed from the session, not the provider
+	response.Metadata.CacheHit = true
+	statuses := make([]models.ProviderStatus, len(response.Metadata.Providers))
+	for i, status := range response.Metadata.Providers {
+		status.Cached = true
+		statuses[i] = status
+	}
+	response.Metadata.Providers = statuses
+	return response, nil
+}
+
+// paginate cuts filters.Limit flights from offset out of a presented search, with
+// cursors to its neighbouring pages. Searches that were not stored still get the
+// page, only without cursors as no later page could be read.
+func (fu *flightUsecase) paginate(response *models.ExpectedSearchResponse, filters models.FilterOptions, offset int) *models.ExpectedSearchResponse {
+	if filters.Limit <= 0 {
+		return response
+	}
+	searchID := response.Metadata.SearchID
+	limit := filters.Limit
+	total := len(response.Flights)
+	end := min(offset+limit, total)
+
+	// The cursor keeps the view but not how it was asked for
+	view := filters
+	view.Limit, view.Cursor, view.Debug = 0, "", false
+	if searchID != "" && end < total {
+		response.Metadata.NextCursor = encodeCursor(pageCursor{SearchID: searchID, Offset: end, Limit: limit, View: view})
+	}
+	if searchID != "" && offset > 0 {
+		response.Metadata.PrevCursor = encodeCursor(pageCursor{SearchID: searchID, Offset: max(0, offset-limit), Limit: limit, View: view})
+	}
+	response.Metadata.TotalResults = total
+	response.Flights = response.Flights[offset:end]
+	return response
+}
@@ -0,0 +1,226 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"flight-aggregator/internal/apperror"
+	"flight-aggregator/internal/models"
+	"flight-aggregator/internal/repository"
+	"flight-aggregator/internal/service"
+	"fmt"
+	"testing"
+	"time"
+)
+
+type mockSearchRepository struct {
+	sessions map[string]models.SearchSession
+}
+
+func newMockSearchRepository() *mockSearchRepository {
+	return &mockSearchRepository{sessions: make(map[string]models.SearchSession)}
+}
+
+func (m *mockSearchRepository) Save(ctx context.Context, session *models.SearchSession) error {
+	m.sessions[session.ID] = *session
+	return nil
+}
+
+func (m *mockSearchRepository) Get(ctx context.Context, id string) (*models.SearchSession, error) {
+	session, ok := m.sessions[id]
+	if !ok {
+		return nil, repository.ErrSearchNotFound
+	}
+	return &session, nil
+}
+
+// fixedFlightService returns the same flights on every search and counts the searches
+type fixedFlightService struct {
+	flights  []models.Flight
+	searches int
+}
+
+func (m *fixedFlightService) GetAllFlights(ctx context.Context, req models.SearchRequest, tenant *models.Tenant) (*service.SearchResult, error) {
+	m.searches++
+	flights := append([]models.Flight(nil), m.flights...)
+	providers := []models.ProviderStatus{{Name: "Garuda Indonesia", Status: models.ProviderStatusSuccess, FlightCount: len(flights)}}
+	return &service.SearchResult{Flights: flights, Providers: providers}, nil
+}
+
+func newFixedFlightService(count int) *fixedFlightService {
+	departure := time.Date(2026, 12, 15, 6, 0, 0, 0, time.UTC)
+	flights := make([]models.Flight, count)
+	for i := range flights {
+		flights[i] = models.Flight{
+			ID:            fmt.Sprintf("GA%d", 100+i),
+			Airline:       "Garuda Indonesia",
+			FlightNumber:  fmt.Sprintf("GA %d", 100+i),
+			Origin:        "CGK",
+			Destination:   "DPS",
+			DepartureTime: departure.Add(time.Duration(i) * time.Hour),
+			Duration:      120,
+			Price:         float64(1000000 + i*10000),
+			Currency:      "IDR",
+			Provider:      "Garuda Indonesia",
+		}
+	}
+	return &fixedFlightService{flights: flights}
+}
+
+func TestFlightUsecase_SearchFlights_Pagination(t *testing.T) {
+	flightService := newFixedFlightService(5)
+	usecase := NewFlightUsecase(flightService, nil, nil, newMockSearchRepository())
+	req := models.SearchRequest{Origin: "CGK", Destination: "DPS", DepartureDate: "2026-12-15", Passengers: 1, CabinClass: "economy"}
+	ctx := context.Background()
+
+	first, err := usecase.SearchFlightsExpected(ctx, req, models.FilterOptions{SortBy: "price_asc", Limit: 2}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(first.Flights) != 2 || first.Flights[0].FlightNumber != "GA 100" || first.Metadata.TotalResults != 5 {
+		t.Fatalf("Expected the 2 cheapest of 5 flights, got %d of %d", len(first.Flights), first.Metadata.TotalResults)
+	}
+	if first.Metadata.SearchID == "" || first.Metadata.NextCursor == "" || first.Metadata.PrevCursor != "" {
+		t.Fatalf("Expected a search id and only a next cursor, got %+v", first.Metadata)
+	}
+
+	second, err := usecase.SearchFlightsExpected(ctx, models.SearchRequest{}, models.FilterOptions{Cursor: first.Metadata.NextCursor}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(second.Flights) != 2 || second.Flights[0].FlightNumber != "GA 102" || second.SearchCriteria.Origin != "CGK" {
+		t.Fatalf("Expected GA102 and GA103 of the same search, got %+v", second.Flights)
+	}
+	if second.Metadata.PrevCursor == "" || second.Metadata.NextCursor == "" {
+		t.Errorf("Expected both cursors on a middle page, got %+v", second.Metadata)
+	}
+
+	last, err := usecase.SearchFlightsExpected(ctx, models.SearchRequest{}, models.FilterOptions{Cursor: second.Metadata.NextCursor}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(last.Flights) != 1 || last.Flights[0].FlightNumber != "GA 104" || last.Metadata.NextCursor != "" {
+		t.Errorf("Expected only GA104 on the last page, got %+v", last.Flights)
+	}
+
+	previous, err := usecase.SearchFlightsExpected(ctx, models.SearchRequest{}, models.FilterOptions{Cursor: last.Metadata.PrevCursor}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if previous.Flights[0].FlightNumber != "GA 102" {
+		t.Errorf("Expected the previous cursor to return to GA102, got %s", previous.Flights[0].FlightNumber)
+	}
+	if flightService.searches != 1 {
+		t.Errorf("Expected pages to be read without searching again, got %d searches", flightService.searches)
+	}
+}
+
+func TestFlightUsecase_SearchFlights_InvalidCursor(t *testing.T) {
+	usecase := NewFlightUsecase(newFixedFlightService(1), nil, nil, newMockSearchRepository())
+
+	_, err := usecase.SearchFlightsExpected(context.Background(), models.SearchRequest{}, models.FilterOptions{Cursor: "not-a-cursor"}, nil)
+	if !errors.Is(err, apperror.ErrValidation) {
+		t.Errorf("Expected a validation error for a malformed cursor, got %v", err)
+	}
+
+	zero := 0
+	forged := []models.FilterOptions{
+		{SortKeys: []string{"cheapest"}},
+		{Diversify: true, MaxPerAirline: &zero},
+		{PreferredDeparture: &models.TimeWindow{From: "6am", To: "10:00"}},
+	}
+	for _, view := range forged {
+		cursor := encodeCursor(pageCursor{SearchID: "abc", Offset: 20, Limit: 20, View: view})
+		_, err = usecase.SearchFlightsExpected(context.Background(), models.SearchRequest{}, models.FilterOptions{Cursor: cursor}, nil)
+		if !errors.Is(err, apperror.ErrValidation) {
+			t.Errorf("Expected a validation error for a cursor carrying %+v, got %v", view, err)
+		}
+	}
+
+	expired := encodeCursor(pageCursor{SearchID: "gone", Offset: 20, Limit: 20})
+	_, err = usecase.SearchFlightsExpected(context.Background(), models.SearchRequest{}, models.FilterOptions{Cursor: expired}, nil)
+	if !errors.Is(err, apperror.ErrNotFound) {
+		t.Errorf("Expected not found for an expired search, got %v", err)
+	}
+}
+
+func TestFlightUsecase_SearchFlights_LimitWithoutStore(t *testing.T) {
+	usecase := NewFlightUsecase(newFixedFlightService(25), nil, nil, nil)
+	req := models.SearchRequest{Origin: "CGK", Destination: "DPS", DepartureDate: "2026-12-15", Passengers: 1, CabinClass: "economy"}
+
+	result, err := usecase.SearchFlightsExpected(context.Background(), req, models.FilterOptions{Limit: 20}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(result.Flights) != 20 || result.Metadata.TotalResults != 25 {
+		t.Errorf("Expected the first 20 of 25 flights when searches cannot be stored, got %d of %d", len(result.Flights), result.Metadata.TotalResults)
+	}
+	if result.Metadata.NextCursor != "" || result.Metadata.PrevCursor != "" {
+		t.Error("Expected no cursors without a stored search")
+	}
+}
+
+func TestFlightUsecase_GetSearch(t *testing.T) {
+	flightService := newFixedFlightService(5)
+	usecase := NewFlightUsecase(flightService, nil, nil, newMockSearchRepository())
+	req := models.SearchRequest{Origin: "CGK", Destination: "DPS", DepartureDate: "2026-12-15", Passengers: 1, CabinClass: "economy"}
+	tenant := &models.Tenant{ID: "acme"}
+	ctx := context.Background()
+
+	search, err := usecase.SearchFlightsExpected(ctx, req, models.FilterOptions{SortBy: "price_asc"}, tenant)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if search.Metadata.SearchID == "" || search.Metadata.ExpiresAt == nil || len(search.Flights) != 5 {
+		t.Fatalf("Expected a stored search of 5 flights, got %+v", search.Metadata)
+	}
+
+	maxPrice := 1020000.0
+	refiltered, err := usecase.GetSearch(ctx, search.Metadata.SearchID, models.FilterOptions{MaxPrice: &maxPrice, SortBy: "price_desc", Limit: 2}, tenant)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(refiltered.Flights) != 2 || refiltered.Flights[0].FlightNumber != "GA 102" || refiltered.Metadata.TotalResults != 3 {
+		t.Fatalf("Expected the 3 flights under the max price, most expensive first, got %+v", refiltered.Flights)
+	}
+	if !refiltered.Metadata.CacheHit || refiltered.SearchCriteria.Destination != "DPS" {
+		t.Errorf("Expected the stored search to be served, got %+v", refiltered.Metadata)
+	}
+	if providers := refiltered.Metadata.Providers; len(providers) != 1 || !providers[0].Cached {
+		t.Errorf("Expected the provider answer to be reported as cached, got %+v", providers)
+	}
+	if search.Metadata.Providers[0].Cached {
+		t.Error("Expected the live search not to report a cached provider answer")
+	}
+
+	// The cursor keeps the view it was cut from
+	next, err := usecase.GetSearch(ctx, search.Metadata.SearchID, models.FilterOptions{Cursor: refiltered.Metadata.NextCursor}, tenant)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(next.Flights) != 1 || next.Flights[0].FlightNumber != "GA 100" {
+		t.Errorf("Expected only GA100 on the next page, got %+v", next.Flights)
+	}
+	if flightService.searches != 1 {
+		t.Errorf("Expected the stored search to be refiltered without searching again, got %d searches", flightService.searches)
+	}
+
+	_, err = usecase.GetSearch(ctx, "other", models.FilterOptions{Cursor: refiltered.Metadata.NextCursor}, tenant)
+	if !errors.Is(err, apperror.ErrValidation) {
+		t.Errorf("Expected a validation error for a cursor of another search, got %v", err)
+	}
+}
+
+func TestFlightUsecase_GetSearch_OtherTenant(t *testing.T) {
+	usecase := NewFlightUsecase(newFixedFlightService(1), nil, nil, newMockSearchRepository())
+	req := models.SearchRequest{Origin: "CGK", Destination: "DPS", DepartureDate: "2026-12-15", Passengers: 1, CabinClass: "economy"}
+
+	search, err := usecase.SearchFlightsExpected(context.Background(), req, models.FilterOptions{}, &models.Tenant{ID: "acme"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, tenant := range []*models.Tenant{{ID: "globex"}, nil} {
+		if _, err := usecase.GetSearch(context.Background(), search.Metadata.SearchID, models.FilterOptions{}, tenant); !errors.Is(err, apperror.ErrNotFound) {
+			t.Errorf("Expected not found for tenant %+v, got %v", tenant, err)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"flight-aggregator/internal/models"
+	"math"
+)
+
+// smartCriteria are a flight's standings for smart results, lower is better on each
+type smartCriteria struct {
+	price     float64
+	duration  int
+	stops     int
+	departure int // minutes outside the preferred departure window
+}
+
+func criteriaOf(flight models.Flight, preferred models.TimeWindow) smartCriteria {
+	return smartCriteria{
+		price:     flight.Price,
+		duration:  flight.Duration,
+		stops:     flight.Stops,
+		departure: preferred.Distance(flight.DepartureTime),
+	}
+}
+
+// dominates reports whether a is at least as good as b on every criterion and
+// better on one
+func (a smartCriteria) dominates(b smartCriteria) bool {
+	if a.price > b.price || a.duration > b.duration || a.stops > b.stops || a.departure > b.departure {
+		return false
+	}
+	return a.price < b.price || a.duration < b.duration || a.stops < b.stops || a.departure < b.departure
+}
+
+// smartResults keeps the Pareto frontier of flights over price, duration, stops and
+// departure convenience, labelling each kept flight with why it is there. Dominated
+// flights are returned as excluded. Departures are measured against preferred, or
+// models.ConvenientDeparture when it is nil.
+func (fu *flightUsecase) smartResults(flights []models.Flight, preferred *models.TimeWindow) ([]models.Flight, []models.ExcludedFlight) {
+	window := models.ConvenientDeparture
+	if preferred != nil {
+		window = *preferred
+	}
+	criteria := make([]smartCriteria, len(flights))
+	for i, flight := range flights {
+		criteria[i] = criteriaOf(flight, window)
+	}
+
+	var frontier []models.Flight
+	var frontierCriteria []smartCriteria
+	var dominated []models.ExcludedFlight
+	for i, flight := range flights {
+		isDominated := false
+		for j := range flights {
+			if j != i && criteria[j].dominates(criteria[i]) {
+				isDominated = true
+				break
+			}
+		}
+		if isDominated {
+			dominated = append(dominated, models.ExcludedFlight{
+				ID:       flight.ID,
+				Provider: flight.Provider,
+				Airline:  flight.Airline,
+				Price:    flight.Price,
+				Filter:   FilterDominated,
+			})
+			continue
+		}
+		frontier = append(frontier, flight)
+		frontierCriteria = append(frontierCriteria, criteria[i])
+	}
+
+	labelFrontier(frontier, frontierCriteria)
+	return frontier, dominated
+}
+
+// labelFrontier names the criteria each flight is best on. The best value of every
+// criterion is always on the frontier, so comparing within it is enough.
+func labelFrontier(frontier []models.Flight, criteria []smartCriteria) {
+	cheapest, cheapestNonstop := math.Inf(1), math.Inf(1)
+	fastest, bestDeparture := math.MaxInt, math.MaxInt
+	for _, c := range criteria {
+		cheapest = min(cheapest, c.price)
+		fastest = min(fastest, c.duration)
+		bestDeparture = min(bestDeparture, c.departure)
+		if c.stops == 0 {
+			cheapestNonstop = min(cheapestNonstop, c.price)
+		}
+	}
+
+	for i, c := range criteria {
+		var labels []string
+		if c.price == cheapest {
+			labels = append(labels, models.SmartLabelCheapest)
+		}
+		if c.duration == fastest {
+			labels = append(labels, models.SmartLabelFastest)
+		}
+		if c.stops == 0 && c.price == cheapestNonstop {
+			labels = append(labels, models.SmartLabelBestNonstop)
+		}
+		if c.departure == bestDeparture {
+			labels = append(labels, models.SmartLabelBestDeparture)
+		}
+		if len(labels) == 0 {
+			labels = append(labels, models.SmartLabelBalanced)
+		}
+		frontier[i].SmartLabels = labels
+	}
+}
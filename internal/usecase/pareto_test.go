@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"context"
+	"flight-aggregator/internal/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFlightUsecase_SmartResults(t *testing.T) {
+	usecase := NewFlightUsecase(&mockFlightService{}, nil, nil, nil).(*flightUsecase)
+	at := func(hour int) time.Time { return time.Date(2026, 12, 15, hour, 0, 0, 0, time.UTC) }
+	flights := []models.Flight{
+		{ID: "cheap-connection", Price: 500000, Duration: 300, Stops: 1, DepartureTime: at(9)},
+		{ID: "fast-direct", Price: 1500000, Duration: 100, Stops: 0, DepartureTime: at(10)},
+		{ID: "cheap-direct", Price: 900000, Duration: 120, Stops: 0, DepartureTime: at(11)},
+		{ID: "worse-direct", Price: 950000, Duration: 130, Stops: 0, DepartureTime: at(12)}, // beaten by cheap-direct on everything
+		{ID: "red-eye", Price: 850000, Duration: 125, Stops: 0, DepartureTime: at(1)},       // cheaper, but at 01:00
+		{ID: "balanced", Price: 700000, Duration: 200, Stops: 1, DepartureTime: at(8)},      // between the cheapest and the directs
+	}
+
+	frontier, dominated := usecase.smartResults(flights, nil)
+
+	if ids := flightIDs(frontier); ids != "cheap-connection,fast-direct,cheap-direct,red-eye,balanced" {
+		t.Errorf("Expected every flight but worse-direct on the frontier, got %s", ids)
+	}
+	if len(dominated) != 1 || dominated[0].ID != "worse-direct" || dominated[0].Filter != FilterDominated {
+		t.Errorf("Expected worse-direct reported as dominated, got %+v", dominated)
+	}
+
+	expectedLabels := map[string]string{
+		"cheap-connection": "cheapest,best_departure",
+		"fast-direct":      "fastest,best_departure",
+		"cheap-direct":     "best_departure",
+		"red-eye":          "best_nonstop",
+		"balanced":         "best_departure",
+	}
+	for _, flight := range frontier {
+		if labels := strings.Join(flight.SmartLabels, ","); labels != expectedLabels[flight.ID] {
+			t.Errorf("Expected %s labelled %s, got %s", flight.ID, expectedLabels[flight.ID], labels)
+		}
+	}
+
+	// A preferred window moves the departure criterion, a flight best on nothing is balanced
+	morning := &models.TimeWindow{From: "08:00", To: "08:30"}
+	frontier, _ = usecase.smartResults(flights, morning)
+	for _, flight := range frontier {
+		if flight.ID == "cheap-direct" && strings.Join(flight.SmartLabels, ",") != models.SmartLabelBalanced {
+			t.Errorf("Expected cheap-direct to be balanced, got %v", flight.SmartLabels)
+		}
+	}
+}
+
+func TestFlightUsecase_SearchFlights_SmartMode(t *testing.T) {
+	// The fixed flights get pricier and later but no faster, only the first is not dominated
+	usecase := NewFlightUsecase(newFixedFlightService(4), nil, nil, nil)
+	req := models.SearchRequest{Origin: "CGK", Destination: "DPS", DepartureDate: "2026-12-15", Passengers: 1, CabinClass: "economy"}
+
+	result, err := usecase.SearchFlightsExpected(context.Background(), req, models.FilterOptions{Mode: models.ResultModeSmart, Debug: true}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(result.Flights) != 1 || result.Flights[0].FlightNumber != "GA 100" || len(result.Flights[0].SmartLabels) == 0 {
+		t.Fatalf("Expected only GA 100 with its labels, got %+v", result.Flights)
+	}
+	if result.Debug.ExcludedByFilter[FilterDominated] != 3 {
+		t.Errorf("Expected 3 dominated flights in the debug output, got %v", result.Debug.ExcludedByFilter)
+	}
+}
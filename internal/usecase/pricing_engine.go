@@ -0,0 +1,156 @@
+package usecase
+
+import (
+	"context"
+	"flight-aggregator/internal/models"
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// PricingContext describes the sale a flight is priced for
+type PricingContext struct {
+	TenantID   string
+	Channel    string
+	CabinClass string
+}
+
+// PricingEngine turns provider fares into selling prices.
+//
+// Matching rules apply in priority order, highest first. If the highest priority
+// matching rule is not stackable it is the only rule applied; otherwise every
+// matching stackable rule applies and non-stackable ones are skipped.
+// Percentages are taken from the net fare, so rules do not compound, and the
+// selling price never drops below zero.
+type PricingEngine struct {
+	mu    sync.RWMutex
+	rules []models.PricingRule
+	load  func() ([]models.PricingRule, error)
+}
+
+// NewPricingEngine loads the initial rules with load, which is called again on every reload
+func NewPricingEngine(load func() ([]models.PricingRule, error)) (*PricingEngine, error) {
+	pe := &PricingEngine{load: load}
+	if _, err := pe.Reload(); err != nil {
+		return nil, err
+	}
+	return pe, nil
+}
+
+// Reload replaces the rules atomically. On error the current rules are kept.
+func (pe *PricingEngine) Reload() (int, error) {
+	rules, err := pe.load()
+	if err != nil {
+		return 0, err
+	}
+
+	sorted := make([]models.PricingRule, len(rules))
+	copy(sorted, rules)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Priority > sorted[j].Priority
+	})
+
+	pe.mu.Lock()
+	pe.rules = sorted
+	pe.mu.Unlock()
+	return len(sorted), nil
+}
+
+// WatchRules reloads the rules every interval until ctx is done
+func (pe *PricingEngine) WatchRules(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := pe.Reload(); err != nil {
+				log.Printf("Failed to reload pricing rules, keeping current rules: %v", err)
+			}
+		}
+	}
+}
+
+// Rules returns the active rules in the order they are evaluated
+func (pe *PricingEngine) Rules() []models.PricingRule {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	rules := make([]models.PricingRule, len(pe.rules))
+	copy(rules, pe.rules)
+	return rules
+}
+
+// Price returns the selling price of a flight and the adjustments that produced it
+func (pe *PricingEngine) Price(flight models.Flight, pc PricingContext) (float64, []models.PriceAdjustment) {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	var adjustments []models.PriceAdjustment
+	price := flight.Price
+	for _, rule := range pe.rules {
+		if !ruleMatches(rule.Match, flight, pc) {
+			continue
+		}
+		if !rule.Stackable {
+			if len(adjustments) > 0 {
+				continue
+			}
+			adjustment := adjustmentFor(rule, flight.Price)
+			return math.Max(0, price+adjustment.Amount), []models.PriceAdjustment{adjustment}
+		}
+
+		adjustment := adjustmentFor(rule, flight.Price)
+		adjustments = append(adjustments, adjustment)
+		price += adjustment.Amount
+	}
+	return math.Max(0, price), adjustments
+}
+
+func adjustmentFor(rule models.PricingRule, netFare float64) models.PriceAdjustment {
+	amount := rule.Value
+	if rule.Mode == models.PricingModePercentage {
+		amount = netFare * rule.Value / 100
+	}
+	if rule.Kind == models.PricingKindDiscount {
+		amount = -amount
+	}
+
+	return models.PriceAdjustment{
+		RuleID:      rule.ID,
+		Kind:        rule.Kind,
+		Description: rule.Description,
+		Amount:      math.Round(amount*100) / 100,
+	}
+}
+
+func ruleMatches(match models.RuleMatch, flight models.Flight, pc PricingContext) bool {
+	if match.DepartureAfter != nil && flight.DepartureTime.Before(*match.DepartureAfter) {
+		return false
+	}
+	if match.DepartureBefore != nil && !flight.DepartureTime.Before(*match.DepartureBefore) {
+		return false
+	}
+	return matchesAny(match.Tenants, pc.TenantID) &&
+		matchesAny(match.Channels, pc.Channel) &&
+		matchesAny(match.Airlines, flight.Airline) &&
+		matchesAny(match.Routes, flight.Origin+"-"+flight.Destination) &&
+		matchesAny(match.CabinClasses, pc.CabinClass)
+}
+
+// matchesAny reports whether value is in values, an empty list matches everything
+func matchesAny(values []string, value string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package usecase
+
+import (
+	"errors"
+	"flight-aggregator/internal/models"
+	"testing"
+	"time"
+)
+
+func staticRules(rules ...models.PricingRule) func() ([]models.PricingRule, error) {
+	return func() ([]models.PricingRule, error) {
+		return rules, nil
+	}
+}
+
+func TestPricingEngine_Price(t *testing.T) {
+	departure := time.Date(2025, 12, 15, 8, 0, 0, 0, time.UTC)
+	windowEnd := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	flight := models.Flight{Airline: "Lion Air", Origin: "CGK", Destination: "DPS", DepartureTime: departure, Price: 1000000}
+
+	markup := models.PricingRule{ID: "b2c-markup", Kind: models.PricingKindMarkup, Mode: models.PricingModePercentage, Value: 10, Priority: 10, Stackable: true, Match: models.RuleMatch{Channels: []string{models.ChannelB2C}}}
+	fee := models.PricingRule{ID: "service-fee", Kind: models.PricingKindFee, Mode: models.PricingModeFixed, Value: 25000, Priority: 1, Stackable: true}
+	routePromo := models.PricingRule{ID: "bali-promo", Kind: models.PricingKindDiscount, Mode: models.PricingModePercentage, Value: 20, Priority: 50, Match: models.RuleMatch{Routes: []string{"CGK-DPS"}}}
+	expiredPromo := models.PricingRule{ID: "early-bird", Kind: models.PricingKindDiscount, Mode: models.PricingModeFixed, Value: 50000, Priority: 100, Match: models.RuleMatch{DepartureBefore: &windowEnd}}
+	acmeOnly := models.PricingRule{ID: "acme", Kind: models.PricingKindDiscount, Mode: models.PricingModeFixed, Value: 1, Priority: 100, Match: models.RuleMatch{Tenants: []string{"acme"}}}
+
+	tests := []struct {
+		name          string
+		rules         []models.PricingRule
+		pc            PricingContext
+		expectedPrice float64
+		expectedRules []string
+	}{
+		{"no rules", nil, PricingContext{Channel: models.ChannelB2C}, 1000000, nil},
+		{"stackable rules add up on the net fare", []models.PricingRule{fee, markup}, PricingContext{Channel: models.ChannelB2C}, 1125000, []string{"b2c-markup", "service-fee"}},
+		{"channel mismatch", []models.PricingRule{fee, markup}, PricingContext{Channel: models.ChannelB2B}, 1025000, []string{"service-fee"}},
+		{"exclusive rule wins when highest", []models.PricingRule{fee, markup, routePromo}, PricingContext{Channel: models.ChannelB2C}, 800000, []string{"bali-promo"}},
+		{"exclusive rule skipped below stackable ones", []models.PricingRule{fee, routePromo, {ID: "top", Kind: models.PricingKindFee, Mode: models.PricingModeFixed, Value: 1000, Priority: 99, Stackable: true}}, PricingContext{}, 1026000, []string{"top", "service-fee"}},
+		{"departure outside window", []models.PricingRule{expiredPromo}, PricingContext{}, 1000000, nil},
+		{"tenant mismatch", []models.PricingRule{acmeOnly}, PricingContext{TenantID: "other"}, 1000000, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, err := NewPricingEngine(staticRules(tt.rules...))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			price, adjustments := engine.Price(flight, tt.pc)
+			if price != tt.expectedPrice {
+				t.Errorf("Expected price %v, got %v", tt.expectedPrice, price)
+			}
+			if len(adjustments) != len(tt.expectedRules) {
+				t.Fatalf("Expected adjustments %v, got %+v", tt.expectedRules, adjustments)
+			}
+			for i, ruleID := range tt.expectedRules {
+				if adjustments[i].RuleID != ruleID {
+					t.Errorf("Adjustment %d: expected rule %s, got %s", i, ruleID, adjustments[i].RuleID)
+				}
+			}
+		})
+	}
+}
+
+func TestPricingEngine_ReloadKeepsRulesOnError(t *testing.T) {
+	rules := []models.PricingRule{{ID: "fee", Kind: models.PricingKindFee, Mode: models.PricingModeFixed, Value: 10}}
+	var loadErr error
+	engine, _ := NewPricingEngine(func() ([]models.PricingRule, error) {
+		return rules, loadErr
+	})
+
+	loadErr = errors.New("invalid file")
+	if _, err := engine.Reload(); err == nil {
+		t.Fatal("Expected reload error")
+	}
+	if len(engine.Rules()) != 1 {
+		t.Errorf("Expected previous rules to be kept, got %d rules", len(engine.Rules()))
+	}
+}
@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"context"
+	"flight-aggregator/internal/apperror"
+	"flight-aggregator/internal/models"
+	"flight-aggregator/internal/repository"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+type PromoUsecase interface {
+	// SavePromo creates or replaces a promo code, keeping its usage count
+	SavePromo(ctx context.Context, promo models.PromoCode) (*models.PromoCode, error)
+	ListPromos(ctx context.Context) ([]models.PromoCode, error)
+	DeletePromo(ctx context.Context, code string) error
+}
+
+type promoUsecase struct {
+	repository repository.PromoRepository
+}
+
+func NewPromoUsecase(repo repository.PromoRepository) PromoUsecase {
+	return &promoUsecase{repository: repo}
+}
+
+func (pu *promoUsecase) SavePromo(ctx context.Context, promo models.PromoCode) (*models.PromoCode, error) {
+	promo.Code = normalizePromoCode(promo.Code)
+	if err := validatePromoCode(promo); err != nil {
+		return nil, err
+	}
+
+	if err := pu.repository.Save(ctx, &promo); err != nil {
+		return nil, apperror.Wrap(apperror.CodeService, err, "Failed to store promo code")
+	}
+	saved, err := pu.repository.Get(ctx, promo.Code)
+	if err != nil {
+		return nil, apperror.Wrap(apperror.CodeService, err, "Failed to load promo code")
+	}
+	return saved, nil
+}
+
+func (pu *promoUsecase) ListPromos(ctx context.Context) ([]models.PromoCode, error) {
+	promos, err := pu.repository.List(ctx)
+	if err != nil {
+		return nil, apperror.Wrap(apperror.CodeService, err, "Failed to list promo codes")
+	}
+	sort.Slice(promos, func(i, j int) bool {
+		return promos[i].Code < promos[j].Code
+	})
+	return promos, nil
+}
+
+func (pu *promoUsecase) DeletePromo(ctx context.Context, code string) error {
+	code = normalizePromoCode(code)
+	_, err := pu.repository.Get(ctx, code)
+	if err == repository.ErrPromoNotFound {
+		return apperror.Newf(apperror.CodeNotFound, "Promo code %s not found", code)
+	}
+	if err != nil {
+		return apperror.Wrap(apperror.CodeService, err, "Failed to load promo code")
+	}
+	if err := pu.repository.Delete(ctx, code); err != nil {
+		return apperror.Wrap(apperror.CodeService, err, "Failed to delete promo code")
+	}
+	return nil
+}
+
+func normalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func validatePromoCode(promo models.PromoCode) error {
+	if promo.Code == "" {
+		return apperror.New(apperror.CodeValidation, "Promo code is required")
+	}
+	switch promo.Mode {
+	case models.PricingModeFixed, models.PricingModePercentage:
+	default:
+		return apperror.New(apperror.CodeValidation, "mode must be fixed or percentage")
+	}
+	if promo.Value <= 0 {
+		return apperror.New(apperror.CodeValidation, "value must be positive")
+	}
+	if promo.Mode == models.PricingModePercentage && promo.Value > 100 {
+		return apperror.New(apperror.CodeValidation, "A percentage discount cannot exceed 100")
+	}
+	if promo.MaxDiscount < 0 || promo.UsageLimit < 0 || promo.MinFare < 0 {
+		return apperror.New(apperror.CodeValidation, "maxDiscount, usageLimit and minFare cannot be negative")
+	}
+	if promo.StartsAt != nil && promo.ExpiresAt != nil && !promo.StartsAt.Before(*promo.ExpiresAt) {
+		return apperror.New(apperror.CodeValidation, "startsAt must be before expiresAt")
+	}
+	return nil
+}
+
+// resolvePromo loads the promo code of a search and checks it can be used now.
+// It returns nil when the search has no promo code.
+func resolvePromo(ctx context.Context, repo repository.PromoRepository, code string, now time.Time) (*models.PromoCode, error) {
+	code = normalizePromoCode(code)
+	if code == "" {
+		return nil, nil
+	}
+	if repo == nil {
+		return nil, apperror.New(apperror.CodeInvalidPromoCode, "Promo codes are not available")
+	}
+
+	promo, err := repo.Get(ctx, code)
+	if err == repository.ErrPromoNotFound {
+		return nil, apperror.Newf(apperror.CodeInvalidPromoCode, "Promo code %s does not exist", code)
+	}
+	if err != nil {
+		return nil, apperror.Wrap(apperror.CodeService, err, "Unable to validate promo code")
+	}
+
+	if promo.StartsAt != nil && now.Before(*promo.StartsAt) {
+		return nil, apperror.Newf(apperror.CodeInvalidPromoCode, "Promo code %s is not valid until %s", code, promo.StartsAt.Format(time.RFC3339))
+	}
+	if promo.ExpiresAt != nil && !now.Before(*promo.ExpiresAt) {
+		return nil, apperror.Newf(apperror.CodeInvalidPromoCode, "Promo code %s expired on %s", code, promo.ExpiresAt.Format(time.RFC3339))
+	}
+	// Concurrent searches may overshoot the limit slightly, uses are counted after the search
+	if promo.UsageLimit > 0 && promo.UsageCount >= promo.UsageLimit {
+		return nil, apperror.Newf(apperror.CodeInvalidPromoCode, "Promo code %s has reached its usage limit", code)
+	}
+	return promo, nil
+}
+
+// promoApplies reports whether a flight at its current selling price is eligible for the promo
+func promoApplies(promo *models.PromoCode, flight models.Flight) bool {
+	return flight.Price >= promo.MinFare &&
+		matchesAny(promo.Airlines, flight.Airline) &&
+		matchesAny(promo.Routes, flight.Origin+"-"+flight.Destination)
+}
+
+// promoDiscount returns the amount taken off a selling price, never more than the price
+func promoDiscount(promo *models.PromoCode, price float64) float64 {
+	discount := promo.Value
+	if promo.Mode == models.PricingModePercentage {
+		discount = price * promo.Value / 100
+		if promo.MaxDiscount > 0 {
+			discount = math.Min(discount, promo.MaxDiscount)
+		}
+	}
+	return math.Round(math.Min(discount, price)*100) / 100
+}
@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"flight-aggregator/internal/apperror"
+	"flight-aggregator/internal/models"
+	"flight-aggregator/internal/repository"
+	"strings"
+	"testing"
+	"time"
+)
+
+type mockPromoRepository struct {
+	promos map[string]models.PromoCode
+}
+
+func newMockPromoRepository(promos ...models.PromoCode) *mockPromoRepository {
+	m := &mockPromoRepository{promos: make(map[string]models.PromoCode)}
+	for _, promo := range promos {
+		m.promos[promo.Code] = promo
+	}
+	return m
+}
+
+func (m *mockPromoRepository) Get(ctx context.Context, code string) (*models.PromoCode, error) {
+	promo, ok := m.promos[code]
+	if !ok {
+		return nil, repository.ErrPromoNotFound
+	}
+	return &promo, nil
+}
+
+func (m *mockPromoRepository) Save(ctx context.Context, promo *models.PromoCode) error {
+	stored := *promo
+	stored.UsageCount = m.promos[promo.Code].UsageCount
+	m.promos[promo.Code] = stored
+	return nil
+}
+
+func (m *mockPromoRepository) List(ctx context.Context) ([]models.PromoCode, error) {
+	var promos []models.PromoCode
+	for _, promo := range m.promos {
+		promos = append(promos, promo)
+	}
+	return promos, nil
+}
+
+func (m *mockPromoRepository) Delete(ctx context.Context, code string) error {
+	delete(m.promos, code)
+	return nil
+}
+
+func (m *mockPromoRepository) IncrementUsage(ctx context.Context, code string) error {
+	promo := m.promos[code]
+	promo.UsageCount++
+	m.promos[code] = promo
+	return nil
+}
+
+func TestResolvePromo(t *testing.T) {
+	now := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+	repo := newMockPromoRepository(
+		models.PromoCode{Code: "BALI10", Mode: models.PricingModePercentage, Value: 10},
+		models.PromoCode{Code: "OLD", Mode: models.PricingModeFixed, Value: 1, ExpiresAt: &past},
+		models.PromoCode{Code: "SOON", Mode: models.PricingModeFixed, Value: 1, StartsAt: &future},
+		models.PromoCode{Code: "USEDUP", Mode: models.PricingModeFixed, Value: 1, UsageLimit: 5, UsageCount: 5},
+	)
+
+	tests := []struct {
+		name        string
+		code        string
+		expectPromo bool
+		expectError string
+	}{
+		{"no code", "", false, ""},
+		{"valid code in lower case", " bali10 ", true, ""},
+		{"unknown code", "NOPE", false, "does not exist"},
+		{"expired", "OLD", false, "expired"},
+		{"not started", "SOON", false, "not valid until"},
+		{"usage limit reached", "USEDUP", false, "usage limit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			promo, err := resolvePromo(context.Background(), repo, tt.code, now)
+			if tt.expectError != "" {
+				if !errors.Is(err, apperror.ErrInvalidPromoCode) || !strings.Contains(err.Error(), tt.expectError) {
+					t.Errorf("Expected INVALID_PROMO_CODE error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if (promo != nil) != tt.expectPromo {
+				t.Errorf("Expected promo %v, got %+v", tt.expectPromo, promo)
+			}
+		})
+	}
+}
+
+func TestPromoDiscount(t *testing.T) {
+	capped := &models.PromoCode{Mode: models.PricingModePercentage, Value: 50, MaxDiscount: 100000}
+	if discount := promoDiscount(capped, 1000000); discount != 100000 {
+		t.Errorf("Expected capped discount 100000, got %v", discount)
+	}
+
+	fixed := &models.PromoCode{Mode: models.PricingModeFixed, Value: 300000}
+	if discount := promoDiscount(fixed, 200000); discount != 200000 {
+		t.Errorf("Expected discount limited to the price, got %v", discount)
+	}
+}
+
+func TestFlightUsecase_SearchFlights_PromoCode(t *testing.T) {
+	repo := newMockPromoRepository(models.PromoCode{
+		Code: "GARUDA15", Mode: models.PricingModePercentage, Value: 15, Airlines: []string{"Garuda Indonesia"}, MinFare: 1000000,
+	})
+	usecase := NewFlightUsecase(&mockFlightService{}, nil, repo, nil)
+	req := models.SearchRequest{Origin: "CGK", Destination: "DPS", DepartureDate: "2025-12-15", Passengers: 1, CabinClass: "economy", PromoCode: "garuda15"}
+
+	result, err := usecase.SearchFlightsExpected(context.Background(), req, models.FilterOptions{SortBy: "price_asc"}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	price := result.Flights[0].Price
+	if price.Amount != 1062500 || price.OriginalAmount == nil || *price.OriginalAmount != 1250000 {
+		t.Errorf("Expected 1250000 discounted to 1062500, got %+v", price)
+	}
+	if result.Metadata.Promo == nil || result.Metadata.Promo.DiscountedFlights != 1 {
+		t.Errorf("Expected promo summary with 1 discounted flight, got %+v", result.Metadata.Promo)
+	}
+	if repo.promos["GARUDA15"].UsageCount != 1 {
+		t.Errorf("Expected promo use to be recorded, got %d", repo.promos["GARUDA15"].UsageCount)
+	}
+}
+
+func TestPromoUsecase_SavePromo_Validation(t *testing.T) {
+	usecase := NewPromoUsecase(newMockPromoRepository())
+
+	_, err := usecase.SavePromo(context.Background(), models.PromoCode{Code: "HALF", Mode: models.PricingModePercentage, Value: 150})
+	if !errors.Is(err, apperror.ErrValidation) {
+		t.Errorf("Expected validation error, got %v", err)
+	}
+
+	saved, err := usecase.SavePromo(context.Background(), models.PromoCode{Code: "half", Mode: models.PricingModePercentage, Value: 50})
+	if err != nil || saved.Code != "HALF" {
+		t.Errorf("Expected promo saved as HALF, got %+v, %v", saved, err)
+	}
+}
@@ -0,0 +1,71 @@
+package usecase
+
+import (
+	"context"
+	"flight-aggregator/internal/apperror"
+	"flight-aggregator/internal/models"
+	"flight-aggregator/internal/repository"
+	"time"
+)
+
+// anonymousPlan names the quota of clients without an API key
+const anonymousPlan = "anonymous"
+
+type QuotaUsecase interface {
+	// ConsumeSearch counts one search for the client. The returned usage has
+	// Exceeded set when the search was rejected.
+	ConsumeSearch(ctx context.Context, clientID string, plan *models.Plan) (*models.Usage, error)
+	// ReleaseSearch gives back a search counted at consumedAt that did not succeed
+	ReleaseSearch(ctx context.Context, clientID string, consumedAt time.Time) error
+	GetUsage(ctx context.Context, clientID string, plan *models.Plan) (*models.Usage, error)
+}
+
+type quotaUsecase struct {
+	repository     repository.QuotaRepository
+	anonymousQuota repository.QuotaLimits
+	now            func() time.Time
+}
+
+// NewQuotaUsecase creates the quota usecase. anonymousQuota applies to clients
+// identified by IP only.
+func NewQuotaUsecase(repo repository.QuotaRepository, anonymousQuota repository.QuotaLimits) QuotaUsecase {
+	return &quotaUsecase{
+		repository:     repo,
+		anonymousQuota: anonymousQuota,
+		now:            time.Now,
+	}
+}
+
+func (qu *quotaUsecase) ConsumeSearch(ctx context.Context, clientID string, plan *models.Plan) (*models.Usage, error) {
+	limits, planName := qu.limitsFor(plan)
+	usage, err := qu.repository.Consume(ctx, clientID, limits, qu.now())
+	if err != nil {
+		return nil, apperror.Wrap(apperror.CodeService, err, "Failed to update search quota")
+	}
+	usage.Plan = planName
+	return usage, nil
+}
+
+func (qu *quotaUsecase) ReleaseSearch(ctx context.Context, clientID string, consumedAt time.Time) error {
+	if err := qu.repository.Release(ctx, clientID, consumedAt); err != nil {
+		return apperror.Wrap(apperror.CodeService, err, "Failed to release search quota")
+	}
+	return nil
+}
+
+func (qu *quotaUsecase) GetUsage(ctx context.Context, clientID string, plan *models.Plan) (*models.Usage, error) {
+	limits, planName := qu.limitsFor(plan)
+	usage, err := qu.repository.Usage(ctx, clientID, limits, qu.now())
+	if err != nil {
+		return nil, apperror.Wrap(apperror.CodeService, err, "Failed to load usage")
+	}
+	usage.Plan = planName
+	return usage, nil
+}
+
+func (qu *quotaUsecase) limitsFor(plan *models.Plan) (repository.QuotaLimits, string) {
+	if plan == nil {
+		return qu.anonymousQuota, anonymousPlan
+	}
+	return repository.QuotaLimits{Daily: plan.SearchesPerDay, Monthly: plan.SearchesPerMonth}, plan.Name
+}
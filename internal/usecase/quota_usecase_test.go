@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"context"
+	"flight-aggregator/internal/models"
+	"flight-aggregator/internal/repository"
+	"testing"
+	"time"
+)
+
+type mockQuotaRepository struct {
+	daily   map[string]int
+	monthly map[string]int
+	limits  repository.QuotaLimits
+}
+
+func newMockQuotaRepository() *mockQuotaRepository {
+	return &mockQuotaRepository{daily: make(map[string]int), monthly: make(map[string]int)}
+}
+
+func (m *mockQuotaRepository) Consume(ctx context.Context, clientID string, limits repository.QuotaLimits, now time.Time) (*models.Usage, error) {
+	m.limits = limits
+	usage := &models.Usage{Client: clientID}
+	switch {
+	case limits.Monthly > 0 && m.monthly[clientID] >= limits.Monthly:
+		usage.Exceeded = models.QuotaPeriodMonthly
+	case limits.Daily > 0 && m.daily[clientID] >= limits.Daily:
+		usage.Exceeded = models.QuotaPeriodDaily
+	default:
+		m.daily[clientID]++
+		m.monthly[clientID]++
+	}
+	usage.Daily = models.QuotaPeriod{Used: m.daily[clientID], Limit: limits.Daily}
+	usage.Monthly = models.QuotaPeriod{Used: m.monthly[clientID], Limit: limits.Monthly}
+	return usage, nil
+}
+
+func (m *mockQuotaRepository) Release(ctx context.Context, clientID string, now time.Time) error {
+	if m.daily[clientID] > 0 {
+		m.daily[clientID]--
+	}
+	if m.monthly[clientID] > 0 {
+		m.monthly[clientID]--
+	}
+	return nil
+}
+
+func (m *mockQuotaRepository) Usage(ctx context.Context, clientID string, limits repository.QuotaLimits, now time.Time) (*models.Usage, error) {
+	m.limits = limits
+	return &models.Usage{
+		Client:  clientID,
+		Daily:   models.QuotaPeriod{Used: m.daily[clientID], Limit: limits.Daily},
+		Monthly: models.QuotaPeriod{Used: m.monthly[clientID], Limit: limits.Monthly},
+	}, nil
+}
+
+func TestQuotaUsecase_ConsumeSearch(t *testing.T) {
+	repo := newMockQuotaRepository()
+	usecase := NewQuotaUsecase(repo, repository.QuotaLimits{Daily: 1})
+	plan := &models.Plan{Name: "partner", SearchesPerDay: 5, SearchesPerMonth: 2}
+
+	for i := 0; i < 2; i++ {
+		usage, err := usecase.ConsumeSearch(context.Background(), "key:k1", plan)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if usage.Exceeded != "" {
+			t.Fatalf("Search %d: expected quota not exceeded, got %s", i+1, usage.Exceeded)
+		}
+	}
+
+	usage, _ := usecase.ConsumeSearch(context.Background(), "key:k1", plan)
+	if usage.Exceeded != models.QuotaPeriodMonthly {
+		t.Errorf("Expected monthly quota exceeded, got %q", usage.Exceeded)
+	}
+	if usage.Plan != "partner" {
+		t.Errorf("Expected plan partner, got %s", usage.Plan)
+	}
+	if usage.Monthly.Used != 2 {
+		t.Errorf("Expected rejected search not to be counted, got %d used", usage.Monthly.Used)
+	}
+}
+
+func TestQuotaUsecase_ReleaseSearch(t *testing.T) {
+	repo := newMockQuotaRepository()
+	usecase := NewQuotaUsecase(repo, repository.QuotaLimits{})
+	plan := &models.Plan{Name: "partner", SearchesPerMonth: 1}
+
+	usecase.ConsumeSearch(context.Background(), "key:k1", plan)
+	if err := usecase.ReleaseSearch(context.Background(), "key:k1", time.Now()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	usage, _ := usecase.ConsumeSearch(context.Background(), "key:k1", plan)
+	if usage.Exceeded != "" {
+		t.Errorf("Expected the released search to be available again, got %q exceeded", usage.Exceeded)
+	}
+}
+
+func TestQuotaUsecase_AnonymousLimits(t *testing.T) {
+	repo := newMockQuotaRepository()
+	usecase := NewQuotaUsecase(repo, repository.QuotaLimits{Daily: 10, Monthly: 100})
+
+	usage, err := usecase.GetUsage(context.Background(), "ip:10.0.0.1", nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if usage.Plan != anonymousPlan {
+		t.Errorf("Expected plan %s, got %s", anonymousPlan, usage.Plan)
+	}
+	if repo.limits.Daily != 10 || repo.limits.Monthly != 100 {
+		t.Errorf("Expected anonymous limits to apply, got %+v", repo.limits)
+	}
+}
@@ -0,0 +1,198 @@
+package usecase
+
+import (
+	"flight-aggregator/internal/config"
+	"flight-aggregator/internal/models"
+	"math"
+	"time"
+)
+
+// Best value scorers, selectable per request or tenant
+const (
+	ScorerAbsolute         = "absolute"
+	ScorerRelative         = "relative"
+	ScorerDepartureTime    = "departure_time"
+	ScorerOvernightLayover = "overnight_layover"
+	ScorerComfort          = "comfort"
+)
+
+// DefaultScorer is used when neither the request nor the tenant picks one
+const DefaultScorer = ScorerAbsolute
+
+// ScorerNames lists the built-in scorers
+var ScorerNames = []string{ScorerAbsolute, ScorerRelative, ScorerDepartureTime, ScorerOvernightLayover, ScorerComfort}
+
+// departureFalloff is how far outside the preferred window a departure still earns
+// part of the departure time weight
+const departureFalloff = 180
+
+// nightEndHour ends the night a layover must not span, which starts at midnight
+const nightEndHour = 5
+
+// ScoringOptions are the settings a result set is ranked with
+type ScoringOptions struct {
+	Weights            models.RankingWeights
+	PreferredDeparture *models.TimeWindow
+}
+
+// Scorer computes the best value score of every flight in a result set, higher
+// ranks first. Scores are only comparable within a single call.
+type Scorer interface {
+	Score(flights []models.Flight, opts ScoringOptions) []models.ScoreBreakdown
+}
+
+// Score components, named after the weight they use
+const (
+	ComponentPrice            = "price"
+	ComponentStops            = "stops"
+	ComponentDuration         = "duration"
+	ComponentDepartureTime    = "departureTime"
+	ComponentOvernightLayover = "overnightLayover"
+)
+
+// NewScorers returns the built-in scorers by name
+func NewScorers(cfg *config.Config) map[string]Scorer {
+	relative := RelativeScorer{}
+	return map[string]Scorer{
+		ScorerAbsolute:         AbsoluteScorer{MaxPrice: cfg.MaxReasonablePrice, MaxDuration: cfg.MaxReasonableDuration},
+		ScorerRelative:         relative,
+		ScorerDepartureTime:    DepartureTimeScorer{Base: relative},
+		ScorerOvernightLayover: OvernightLayoverScorer{Base: relative},
+		ScorerComfort:          OvernightLayoverScorer{Base: DepartureTimeScorer{Base: relative}},
+	}
+}
+
+func isScorer(name string) bool {
+	return matchesAny(ScorerNames, name)
+}
+
+// AbsoluteScorer normalizes price and duration against fixed ceilings, so a
+// flight scores the same whatever else the search returned
+type AbsoluteScorer struct {
+	MaxPrice    float64
+	MaxDuration int
+}
+
+func (s AbsoluteScorer) Score(flights []models.Flight, opts ScoringOptions) []models.ScoreBreakdown {
+	scores := make([]models.ScoreBreakdown, len(flights))
+	for i, flight := range flights {
+		priceScore := math.Max(0, 1.0-(flight.Price/s.MaxPrice))
+
+		stopsScore := 1.0
+		if flight.Stops > 0 {
+			stopsScore = 0.7
+		}
+
+		durationScore := math.Max(0, 1.0-(float64(flight.Duration)/float64(s.MaxDuration)))
+
+		w := opts.Weights
+		scores[i].Add(ComponentPrice, priceScore, w.Price)
+		scores[i].Add(ComponentStops, stopsScore, w.Stops)
+		scores[i].Add(ComponentDuration, durationScore, w.Duration)
+	}
+	return scores
+}
+
+// RelativeScorer normalizes price, stops and duration between the best and worst
+// flight of the result set
+type RelativeScorer struct{}
+
+func (RelativeScorer) Score(flights []models.Flight, opts ScoringOptions) []models.ScoreBreakdown {
+	prices := make([]float64, len(flights))
+	stops := make([]float64, len(flights))
+	durations := make([]float64, len(flights))
+	for i, flight := range flights {
+		prices[i] = flight.Price
+		stops[i] = float64(flight.Stops)
+		durations[i] = float64(flight.Duration)
+	}
+	priceScores := normalizeLowerBetter(prices)
+	stopsScores := normalizeLowerBetter(stops)
+	durationScores := normalizeLowerBetter(durations)
+
+	w := opts.Weights
+	scores := make([]models.ScoreBreakdown, len(flights))
+	for i := range flights {
+		scores[i].Add(ComponentPrice, priceScores[i], w.Price)
+		scores[i].Add(ComponentStops, stopsScores[i], w.Stops)
+		scores[i].Add(ComponentDuration, durationScores[i], w.Duration)
+	}
+	return scores
+}
+
+// normalizeLowerBetter maps the lowest value to 1 and the highest to 0. Equal
+// values all score 1.
+func normalizeLowerBetter(values []float64) []float64 {
+	lowest, highest := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		lowest = math.Min(lowest, v)
+		highest = math.Max(highest, v)
+	}
+
+	normalized := make([]float64, len(values))
+	for i, v := range values {
+		if highest == lowest {
+			normalized[i] = 1
+			continue
+		}
+		normalized[i] = (highest - v) / (highest - lowest)
+	}
+	return normalized
+}
+
+// DepartureTimeScorer adds the departure time weight to flights leaving in the
+// preferred window, fading out over departureFalloff minutes outside it
+type DepartureTimeScorer struct {
+	Base Scorer
+}
+
+func (s DepartureTimeScorer) Score(flights []models.Flight, opts ScoringOptions) []models.ScoreBreakdown {
+	scores := s.Base.Score(flights, opts)
+	if opts.PreferredDeparture == nil {
+		return scores
+	}
+	for i, flight := range flights {
+		distance := opts.PreferredDeparture.Distance(flight.DepartureTime)
+		closeness := math.Max(0, 1-float64(distance)/departureFalloff)
+		scores[i].Add(ComponentDepartureTime, closeness, opts.Weights.DepartureTime)
+	}
+	return scores
+}
+
+// OvernightLayoverScorer subtracts the overnight layover weight from flights
+// with a connection spanning the night
+type OvernightLayoverScorer struct {
+	Base Scorer
+}
+
+func (s OvernightLayoverScorer) Score(flights []models.Flight, opts ScoringOptions) []models.ScoreBreakdown {
+	scores := s.Base.Score(flights, opts)
+	for i, flight := range flights {
+		penalty := 0.0
+		if hasOvernightLayover(flight) {
+			penalty = -1
+		}
+		scores[i].Add(ComponentOvernightLayover, penalty, opts.Weights.OvernightLayover)
+	}
+	return scores
+}
+
+// hasOvernightLayover reports whether a layover overlaps midnight to nightEndHour.
+// Layovers without a start time are assumed to sit in the middle of the journey.
+func hasOvernightLayover(flight models.Flight) bool {
+	for _, layover := range flight.Layovers {
+		var start time.Time
+		if layover.Start != nil {
+			start = *layover.Start
+		} else {
+			flying := time.Duration(flight.Duration-layover.Minutes) * time.Minute
+			start = flight.DepartureTime.Add(flying / 2)
+		}
+		end := start.Add(time.Duration(layover.Minutes) * time.Minute)
+
+		if start.Hour() < nightEndHour || end.YearDay() != start.YearDay() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"flight-aggregator/internal/apperror"
+	"flight-aggregator/internal/models"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRelativeScorer(t *testing.T) {
+	flights := []models.Flight{
+		{Price: 1000000, Stops: 0, Duration: 100},
+		{Price: 2000000, Stops: 1, Duration: 200},
+		{Price: 1500000, Stops: 0, Duration: 150},
+	}
+	weights := models.RankingWeights{Price: 0.5, Stops: 0.3, Duration: 0.2}
+
+	scores := RelativeScorer{}.Score(flights, ScoringOptions{Weights: weights})
+	if scores[0].Total != 1 || scores[1].Total != 0 {
+		t.Errorf("Expected the best flight at 1 and the worst at 0, got %+v", scores)
+	}
+	if want := 0.65; math.Abs(scores[2].Total-want) > 1e-9 {
+		t.Errorf("Expected the middle flight at %v, got %v", want, scores[2].Total)
+	}
+}
+
+func TestDepartureTimeScorer(t *testing.T) {
+	day := time.Date(2026, 12, 15, 0, 0, 0, 0, time.UTC)
+	flights := []models.Flight{
+		{Price: 1000000, DepartureTime: day.Add(7*time.Hour + 30*time.Minute)},
+		{Price: 1000000, DepartureTime: day.Add(10 * time.Hour)},
+		{Price: 1000000, DepartureTime: day.Add(20 * time.Hour)},
+	}
+	opts := ScoringOptions{
+		Weights:            models.RankingWeights{Price: 1, DepartureTime: 0.5},
+		PreferredDeparture: &models.TimeWindow{From: "09:00", To: "12:00"},
+	}
+
+	scores := DepartureTimeScorer{Base: RelativeScorer{}}.Score(flights, opts)
+	if scores[1].Total != 1.5 {
+		t.Errorf("Expected the flight in the window to earn the full departure weight, got %v", scores[1].Total)
+	}
+	if scores[0].Total != 1.25 {
+		t.Errorf("Expected the flight 90 minutes early to earn half of it, got %v", scores[0].Total)
+	}
+	if scores[2].Total != 1 {
+		t.Errorf("Expected the evening flight to earn none of it, got %v", scores[2].Total)
+	}
+}
+
+func TestOvernightLayoverScorer(t *testing.T) {
+	departure := time.Date(2026, 12, 15, 20, 0, 0, 0, time.UTC)
+	lateStart := departure.Add(4 * time.Hour)
+	flights := []models.Flight{
+		{Price: 1000000, DepartureTime: departure, Layovers: []models.Layover{{Airport: "SUB", Minutes: 60, Start: &lateStart}}},
+		{Price: 1000000, DepartureTime: departure.Add(-12 * time.Hour), Duration: 300, Layovers: []models.Layover{{Airport: "SUB", Minutes: 60}}},
+		{Price: 1000000, DepartureTime: departure, Duration: 600, Layovers: []models.Layover{{Airport: "SUB", Minutes: 120}}},
+	}
+	opts := ScoringOptions{Weights: models.RankingWeights{Price: 1, OvernightLayover: 0.5}}
+
+	scores := OvernightLayoverScorer{Base: RelativeScorer{}}.Score(flights, opts)
+	if scores[0].Total != 0.5 {
+		t.Errorf("Expected a layover starting after midnight to be penalized, got %v", scores[0].Total)
+	}
+	if scores[1].Total != 1 {
+		t.Errorf("Expected a daytime layover not to be penalized, got %v", scores[1].Total)
+	}
+	if scores[2].Total != 0.5 {
+		t.Errorf("Expected an estimated layover crossing midnight to be penalized, got %v", scores[2].Total)
+	}
+}
+
+func TestFlightUsecase_RankingFor(t *testing.T) {
+	usecase := NewFlightUsecase(&mockFlightService{}, nil, nil, nil).(*flightUsecase)
+	tenant := &models.Tenant{ID: "acme", Scorer: ScorerRelative, RankingWeights: &models.RankingWeights{Price: 1}}
+
+	scorer, opts, err := usecase.rankingFor(models.FilterOptions{}, tenant)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if scorer != ScorerRelative || opts.Weights.Price != 1 {
+		t.Errorf("Expected the tenant's scorer and weights, got %s %+v", scorer, opts.Weights)
+	}
+
+	filters := models.FilterOptions{Scorer: ScorerAbsolute, RankingWeights: &models.RankingWeights{Stops: 1}}
+	scorer, opts, err = usecase.rankingFor(filters, tenant)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if scorer != ScorerAbsolute || opts.Weights.Stops != 1 || opts.Weights.Price != 0 {
+		t.Errorf("Expected the request to override the tenant, got %s %+v", scorer, opts.Weights)
+	}
+
+	req := models.SearchRequest{Origin: "CGK", Destination: "DPS", DepartureDate: "2025-12-15", Passengers: 1, CabinClass: "economy"}
+	_, err = usecase.SearchFlightsExpected(context.Background(), req, models.FilterOptions{Scorer: "cheapest"}, nil)
+	if !errors.Is(err, apperror.ErrValidation) {
+		t.Errorf("Expected a validation error for an unknown scorer, got %v", err)
+	}
+}
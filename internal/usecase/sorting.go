@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"cmp"
+	"flight-aggregator/internal/models"
+	"sort"
+	"strings"
+)
+
+// flightComparator orders two flights on one sort key, negative when a comes first
+type flightComparator func(a, b *models.Flight) int
+
+// sortComparators implements every key of models.SortOptions
+var sortComparators = map[string]flightComparator{
+	"price_asc":           func(a, b *models.Flight) int { return cmp.Compare(a.Price, b.Price) },
+	"price_desc":          func(a, b *models.Flight) int { return cmp.Compare(b.Price, a.Price) },
+	"duration_asc":        func(a, b *models.Flight) int { return cmp.Compare(a.Duration, b.Duration) },
+	"duration_desc":       func(a, b *models.Flight) int { return cmp.Compare(b.Duration, a.Duration) },
+	"departure_time":      func(a, b *models.Flight) int { return a.DepartureTime.Compare(b.DepartureTime) },
+	"departure_time_desc": func(a, b *models.Flight) int { return b.DepartureTime.Compare(a.DepartureTime) },
+	"arrival_time":        func(a, b *models.Flight) int { return a.ArrivalTime.Compare(b.ArrivalTime) },
+	"arrival_time_desc":   func(a, b *models.Flight) int { return b.ArrivalTime.Compare(a.ArrivalTime) },
+	"stops_asc":           func(a, b *models.Flight) int { return cmp.Compare(a.Stops, b.Stops) },
+	"stops_desc":          func(a, b *models.Flight) int { return cmp.Compare(b.Stops, a.Stops) },
+	"airline_asc":         func(a, b *models.Flight) int { return strings.Compare(a.Airline, b.Airline) },
+	"airline_desc":        func(a, b *models.Flight) int { return strings.Compare(b.Airline, a.Airline) },
+	"seats_asc":           func(a, b *models.Flight) int { return cmp.Compare(a.AvailableSeats, b.AvailableSeats) },
+	"seats_desc":          func(a, b *models.Flight) int { return cmp.Compare(b.AvailableSeats, a.AvailableSeats) },
+	"best_value":          func(a, b *models.Flight) int { return cmp.Compare(b.BestValue, a.BestValue) },
+}
+
+// sortFlights orders flights by each key in turn. Flights equal on every key are
+// ordered by compareIdentity, so identical searches always return identical pages
+// whatever order the providers answered in.
+func (fu *flightUsecase) sortFlights(flights []models.Flight, keys []string) {
+	comparators := make([]flightComparator, 0, len(keys)+1)
+	for _, key := range keys {
+		if comparator, ok := sortComparators[key]; ok {
+			comparators = append(comparators, comparator)
+		}
+	}
+	comparators = append(comparators, compareIdentity)
+
+	sort.SliceStable(flights, func(i, j int) bool {
+		for _, comparator := range comparators {
+			if c := comparator(&flights[i], &flights[j]); c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+}
+
+// compareIdentity is the final tie-breaker, a flight is unique per provider, number
+// and departure
+func compareIdentity(a, b *models.Flight) int {
+	return cmp.Or(
+		strings.Compare(a.Provider, b.Provider),
+		strings.Compare(a.FlightNumber, b.FlightNumber),
+		a.DepartureTime.Compare(b.DepartureTime),
+		strings.Compare(a.ID, b.ID),
+	)
+}
@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"flight-aggregator/internal/models"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestSortComparators_CoverSortOptions(t *testing.T) {
+	for _, key := range models.SortOptions {
+		if _, ok := sortComparators[key]; !ok {
+			t.Errorf("Sort option %s has no comparator", key)
+		}
+	}
+}
+
+func TestFlightUsecase_SortFlights_MultiKey(t *testing.T) {
+	usecase := NewFlightUsecase(&mockFlightService{}, nil, nil, nil).(*flightUsecase)
+	morning := time.Date(2026, 12, 15, 8, 0, 0, 0, time.UTC)
+	flights := []models.Flight{
+		{ID: "a", Provider: "Lion Air", Stops: 1, Price: 500000, DepartureTime: morning},
+		{ID: "b", Provider: "Garuda Indonesia", Stops: 0, Price: 900000, DepartureTime: morning},
+		{ID: "c", Provider: "Batik Air", Stops: 0, Price: 700000, DepartureTime: morning.Add(time.Hour)},
+		{ID: "d", Provider: "AirAsia", Stops: 0, Price: 700000, DepartureTime: morning},
+	}
+
+	usecase.sortFlights(flights, []string{"stops_asc", "price_asc", "departure_time"})
+
+	expected := []string{"d", "c", "b", "a"}
+	for i, id := range expected {
+		if flights[i].ID != id {
+			t.Fatalf("Expected order %v, got %s at %d", expected, flights[i].ID, i)
+		}
+	}
+}
+
+func TestFlightUsecase_SortFlights_DeterministicTies(t *testing.T) {
+	usecase := NewFlightUsecase(&mockFlightService{}, nil, nil, nil).(*flightUsecase)
+	departure := time.Date(2026, 12, 15, 8, 0, 0, 0, time.UTC)
+	var flights []models.Flight
+	for _, provider := range []string{"Lion Air", "AirAsia", "Garuda Indonesia", "Batik Air"} {
+		for _, number := range []string{"100", "200"} {
+			flights = append(flights, models.Flight{ID: number, Provider: provider, FlightNumber: number, Price: 750000, DepartureTime: departure})
+		}
+	}
+
+	usecase.sortFlights(flights, []string{"price_asc"})
+	first := append([]models.Flight(nil), flights...)
+
+	for run := 0; run < 10; run++ {
+		rand.Shuffle(len(flights), func(i, j int) { flights[i], flights[j] = flights[j], flights[i] })
+		usecase.sortFlights(flights, []string{"price_asc"})
+		for i := range flights {
+			if flights[i].Provider != first[i].Provider || flights[i].FlightNumber != first[i].FlightNumber {
+				t.Fatalf("Expected the same order for equal prices, run %d differs at %d", run, i)
+			}
+		}
+	}
+	if first[0].Provider != "AirAsia" || first[0].FlightNumber != "100" {
+		t.Errorf("Expected ties broken by provider then flight number, got %s %s first", first[0].Provider, first[0].FlightNumber)
+	}
+}
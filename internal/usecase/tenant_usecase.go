@@ -0,0 +1,161 @@
+package usecase
+
+import (
+	"context"
+	"flight-aggregator/internal/apperror"
+	"flight-aggregator/internal/models"
+	"flight-aggregator/internal/providers"
+	"flight-aggregator/internal/repository"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var currencyCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+type TenantUsecase interface {
+	// SaveTenant creates or replaces a tenant's configuration
+	SaveTenant(ctx context.Context, tenant models.Tenant) (*models.Tenant, error)
+	GetTenant(ctx context.Context, id string) (*models.Tenant, error)
+	ListTenants(ctx context.Context) ([]models.Tenant, error)
+	DeleteTenant(ctx context.Context, id string) error
+}
+
+type tenantUsecase struct {
+	repository repository.TenantRepository
+}
+
+func NewTenantUsecase(repo repository.TenantRepository) TenantUsecase {
+	return &tenantUsecase{repository: repo}
+}
+
+// SaveTenant returns the stored tenant with its credentials redacted. Credentials
+// sent back masked, as a GET returns them, keep their stored value.
+func (tu *tenantUsecase) SaveTenant(ctx context.Context, tenant models.Tenant) (*models.Tenant, error) {
+	if err := validateTenant(tenant); err != nil {
+		return nil, err
+	}
+	if err := tu.keepMaskedCredentials(ctx, &tenant); err != nil {
+		return nil, err
+	}
+
+	if err := tu.repository.Save(ctx, &tenant); err != nil {
+		return nil, apperror.Wrap(apperror.CodeService, err, "Failed to store tenant")
+	}
+
+	redacted := tenant.Redacted()
+	return &redacted, nil
+}
+
+// keepMaskedCredentials replaces masked credential values with the stored ones,
+// rejecting a masked value there is nothing stored for
+func (tu *tenantUsecase) keepMaskedCredentials(ctx context.Context, tenant *models.Tenant) error {
+	var stored *models.Tenant
+	for provider, fields := range tenant.ProviderCredentials {
+		for field, value := range fields {
+			if value != models.CredentialMask {
+				continue
+			}
+			if stored == nil {
+				var err error
+				stored, err = tu.repository.Get(ctx, tenant.ID)
+				if err == repository.ErrTenantNotFound {
+					stored = &models.Tenant{}
+				} else if err != nil {
+					return apperror.Wrap(apperror.CodeService, err, "Failed to load tenant")
+				}
+			}
+			storedValue, ok := stored.ProviderCredentials[provider][field]
+			if !ok {
+				return apperror.Newf(apperror.CodeValidation, "Credential %s of %s is masked but none is stored", field, provider)
+			}
+			fields[field] = storedValue
+		}
+	}
+	return nil
+}
+
+func (tu *tenantUsecase) GetTenant(ctx context.Context, id string) (*models.Tenant, error) {
+	tenant, err := tu.repository.Get(ctx, id)
+	if err == repository.ErrTenantNotFound {
+		return nil, apperror.Newf(apperror.CodeNotFound, "Tenant %s not found", id)
+	}
+	if err != nil {
+		return nil, apperror.Wrap(apperror.CodeService, err, "Failed to load tenant")
+	}
+
+	redacted := tenant.Redacted()
+	return &redacted, nil
+}
+
+func (tu *tenantUsecase) ListTenants(ctx context.Context) ([]models.Tenant, error) {
+	tenants, err := tu.repository.List(ctx)
+	if err != nil {
+		return nil, apperror.Wrap(apperror.CodeService, err, "Failed to list tenants")
+	}
+	for i := range tenants {
+		tenants[i] = tenants[i].Redacted()
+	}
+	sort.Slice(tenants, func(i, j int) bool {
+		return tenants[i].ID < tenants[j].ID
+	})
+	return tenants, nil
+}
+
+func (tu *tenantUsecase) DeleteTenant(ctx context.Context, id string) error {
+	if _, err := tu.GetTenant(ctx, id); err != nil {
+		return err
+	}
+	if err := tu.repository.Delete(ctx, id); err != nil {
+		return apperror.Wrap(apperror.CodeService, err, "Failed to delete tenant")
+	}
+	return nil
+}
+
+func validateTenant(tenant models.Tenant) error {
+	if tenant.ID == "" || tenant.Name == "" {
+		return apperror.New(apperror.CodeValidation, "Tenant id and name are required")
+	}
+
+	known := make(map[string]bool)
+	for _, name := range providers.Names() {
+		known[name] = true
+	}
+	for _, name := range tenant.EnabledProviders {
+		if !known[name] {
+			return apperror.Newf(apperror.CodeValidation, "Unknown provider %s", name)
+		}
+	}
+	for name := range tenant.ProviderCredentials {
+		if !known[name] {
+			return apperror.Newf(apperror.CodeValidation, "Credentials given for unknown provider %s", name)
+		}
+	}
+
+	if tenant.DefaultCurrency != "" && !currencyCodePattern.MatchString(tenant.DefaultCurrency) {
+		return apperror.New(apperror.CodeValidation, "defaultCurrency must be an ISO 4217 code such as IDR")
+	}
+	if weights := tenant.RankingWeights; weights != nil {
+		if weights.Price < 0 || weights.Stops < 0 || weights.Duration < 0 || weights.DepartureTime < 0 || weights.OvernightLayover < 0 {
+			return apperror.New(apperror.CodeValidation, "Ranking weights cannot be negative")
+		}
+		if !weights.HasPositive() {
+			return apperror.New(apperror.CodeValidation, "At least one ranking weight must be positive")
+		}
+	}
+	if tenant.Scorer != "" && !isScorer(tenant.Scorer) {
+		return apperror.Newf(apperror.CodeValidation, "scorer must be one of %s", strings.Join(ScorerNames, ", "))
+	}
+	switch tenant.Channel {
+	case "", models.ChannelB2C, models.ChannelB2B:
+	default:
+		return apperror.New(apperror.CodeValidation, "channel must be b2c or b2b")
+	}
+	if tenant.MaxResults < 0 {
+		return apperror.New(apperror.CodeValidation, "maxResults cannot be negative")
+	}
+	if tenant.TravelPolicy != nil {
+		return validateTravelPolicy(tenant.TravelPolicy)
+	}
+	return nil
+}
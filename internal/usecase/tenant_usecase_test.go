@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"flight-aggregator/internal/apperror"
+	"flight-aggregator/internal/models"
+	"flight-aggregator/internal/repository"
+	"testing"
+)
+
+type mockTenantRepository struct {
+	tenants map[string]models.Tenant
+}
+
+func newMockTenantRepository() *mockTenantRepository {
+	return &mockTenantRepository{tenants: make(map[string]models.Tenant)}
+}
+
+func (m *mockTenantRepository) Get(ctx context.Context, id string) (*models.Tenant, error) {
+	tenant, ok := m.tenants[id]
+	if !ok {
+		return nil, repository.ErrTenantNotFound
+	}
+	return &tenant, nil
+}
+
+func (m *mockTenantRepository) Save(ctx context.Context, tenant *models.Tenant) error {
+	m.tenants[tenant.ID] = *tenant
+	return nil
+}
+
+func (m *mockTenantRepository) List(ctx context.Context) ([]models.Tenant, error) {
+	var tenants []models.Tenant
+	for _, tenant := range m.tenants {
+		tenants = append(tenants, tenant)
+	}
+	return tenants, nil
+}
+
+func (m *mockTenantRepository) Delete(ctx context.Context, id string) error {
+	delete(m.tenants, id)
+	return nil
+}
+
+func TestTenantUsecase_SaveTenant(t *testing.T) {
+	repo := newMockTenantRepository()
+	usecase := NewTenantUsecase(repo)
+
+	saved, err := usecase.SaveTenant(context.Background(), models.Tenant{
+		ID:                  "acme",
+		Name:                "Acme Travel",
+		EnabledProviders:    []string{"Garuda Indonesia", "AirAsia"},
+		ProviderCredentials: map[string]map[string]string{"AirAsia": {"api_key": "secret"}},
+		DefaultCurrency:     "IDR",
+		MaxResults:          20,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if saved.ProviderCredentials["AirAsia"]["api_key"] == "secret" {
+		t.Error("Expected credentials to be redacted in the response")
+	}
+	if repo.tenants["acme"].ProviderCredentials["AirAsia"]["api_key"] != "secret" {
+		t.Error("Expected credentials to be stored")
+	}
+}
+
+func TestTenantUsecase_SaveTenant_KeepsMaskedCredentials(t *testing.T) {
+	repo := newMockTenantRepository()
+	usecase := NewTenantUsecase(repo)
+	usecase.SaveTenant(context.Background(), models.Tenant{
+		ID:                  "acme",
+		Name:                "Acme Travel",
+		ProviderCredentials: map[string]map[string]string{"AirAsia": {"api_key": "secret", "agent_id": "42"}},
+	})
+
+	// An edited tenant is sent back with the masked credentials of the GET
+	tenant, _ := usecase.GetTenant(context.Background(), "acme")
+	tenant.MaxResults = 10
+	tenant.ProviderCredentials["AirAsia"]["agent_id"] = "43"
+	if _, err := usecase.SaveTenant(context.Background(), *tenant); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	credentials := repo.tenants["acme"].ProviderCredentials["AirAsia"]
+	if credentials["api_key"] != "secret" || credentials["agent_id"] != "43" {
+		t.Errorf("Expected the masked credential to keep its stored value, got %v", credentials)
+	}
+}
+
+func TestTenantUsecase_SaveTenant_Validation(t *testing.T) {
+	tests := []struct {
+		name   string
+		tenant models.Tenant
+	}{
+		{"missing id", models.Tenant{Name: "Acme"}},
+		{"unknown provider", models.Tenant{ID: "acme", Name: "Acme", EnabledProviders: []string{"Pan Am"}}},
+		{"credentials for unknown provider", models.Tenant{ID: "acme", Name: "Acme", ProviderCredentials: map[string]map[string]string{"Pan Am": {}}}},
+		{"invalid currency", models.Tenant{ID: "acme", Name: "Acme", DefaultCurrency: "rupiah"}},
+		{"negative weight", models.Tenant{ID: "acme", Name: "Acme", RankingWeights: &models.RankingWeights{Price: -1, Stops: 1}}},
+		{"zero weights", models.Tenant{ID: "acme", Name: "Acme", RankingWeights: &models.RankingWeights{}}},
+		{"negative max results", models.Tenant{ID: "acme", Name: "Acme", MaxResults: -1}},
+		{"masked credential never stored", models.Tenant{ID: "acme", Name: "Acme", ProviderCredentials: map[string]map[string]string{"AirAsia": {"api_key": models.CredentialMask}}}},
+	}
+
+	usecase := NewTenantUsecase(newMockTenantRepository())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := usecase.SaveTenant(context.Background(), tt.tenant)
+			if !errors.Is(err, apperror.ErrValidation) {
+				t.Errorf("Expected validation error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestTenantUsecase_DeleteTenant_NotFound(t *testing.T) {
+	usecase := NewTenantUsecase(newMockTenantRepository())
+
+	err := usecase.DeleteTenant(context.Background(), "missing")
+	if !errors.Is(err, apperror.ErrNotFound) {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+}
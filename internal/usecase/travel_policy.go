@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"flight-aggregator/internal/apperror"
+	"flight-aggregator/internal/models"
+	"fmt"
+	"time"
+)
+
+// evaluatePolicy checks a flight at its selling price against a travel policy and
+// returns the verdict with every broken rule
+func evaluatePolicy(policy *models.TravelPolicy, flight models.Flight, cabinClass string, now time.Time) *models.PolicyStatus {
+	var reasons []string
+
+	routeClass := models.RouteClassOf(flight.Duration)
+	if maxFare, ok := policy.MaxFares[routeClass]; ok && flight.Price > maxFare {
+		reasons = append(reasons, fmt.Sprintf("fare %.0f exceeds the %s limit of %.0f", flight.Price, routeClass, maxFare))
+	}
+
+	if cabins, ok := allowedCabins(policy.CabinRules, flight.Duration); ok && !matchesAny(cabins, cabinClass) {
+		reasons = append(reasons, fmt.Sprintf("%s cabin is not allowed on a %d minute flight", cabinClass, flight.Duration))
+	}
+
+	if !matchesAny(policy.PreferredAirlines, flight.Airline) {
+		reasons = append(reasons, fmt.Sprintf("%s is not a preferred airline", flight.Airline))
+	}
+
+	if policy.MinLeadDays > 0 {
+		leadDays := int(flight.DepartureTime.Sub(now).Hours() / 24)
+		if leadDays < policy.MinLeadDays {
+			reasons = append(reasons, fmt.Sprintf("booked %d days ahead, policy requires %d", leadDays, policy.MinLeadDays))
+		}
+	}
+
+	return &models.PolicyStatus{InPolicy: len(reasons) == 0, Reasons: reasons}
+}
+
+// allowedCabins returns the cabins of the rule covering a flight duration. It
+// reports false when no rule covers it, which allows every cabin.
+func allowedCabins(rules []models.CabinRule, duration int) ([]string, bool) {
+	var match *models.CabinRule
+	for i, rule := range rules {
+		if rule.MinDuration <= duration && (match == nil || rule.MinDuration > match.MinDuration) {
+			match = &rules[i]
+		}
+	}
+	if match == nil {
+		return nil, false
+	}
+	return match.Cabins, true
+}
+
+func validateTravelPolicy(policy *models.TravelPolicy) error {
+	for routeClass, maxFare := range policy.MaxFares {
+		switch routeClass {
+		case models.RouteClassShortHaul, models.RouteClassMediumHaul, models.RouteClassLongHaul:
+		default:
+			return apperror.Newf(apperror.CodeValidation, "Unknown route class %s, use short_haul, medium_haul or long_haul", routeClass)
+		}
+		if maxFare <= 0 {
+			return apperror.Newf(apperror.CodeValidation, "Maximum fare for %s must be positive", routeClass)
+		}
+	}
+	for _, rule := range policy.CabinRules {
+		if rule.MinDuration < 0 || len(rule.Cabins) == 0 {
+			return apperror.New(apperror.CodeValidation, "Cabin rules need a non-negative minDuration and at least one cabin")
+		}
+	}
+	if policy.MinLeadDays < 0 {
+		return apperror.New(apperror.CodeValidation, "minLeadDays cannot be negative")
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package usecase
+
+import (
+	"context"
+	"flight-aggregator/internal/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEvaluatePolicy(t *testing.T) {
+	now := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	policy := &models.TravelPolicy{
+		MaxFares: map[string]float64{models.RouteClassShortHaul: 1500000},
+		CabinRules: []models.CabinRule{
+			{MinDuration: 0, Cabins: []string{"economy"}},
+			{MinDuration: 360, Cabins: []string{"economy", "business"}},
+		},
+		PreferredAirlines: []string{"Garuda Indonesia", "Batik Air"},
+		MinLeadDays:       7,
+	}
+	inPolicy := models.Flight{Airline: "Garuda Indonesia", Duration: 110, Price: 1200000, DepartureTime: now.AddDate(0, 0, 14)}
+
+	tests := []struct {
+		name           string
+		flight         func(models.Flight) models.Flight
+		cabinClass     string
+		expectedReason string
+	}{
+		{"in policy", func(f models.Flight) models.Flight { return f }, "economy", ""},
+		{"fare over route class limit", func(f models.Flight) models.Flight { f.Price = 1600000; return f }, "economy", "exceeds the short_haul limit"},
+		{"cabin not allowed on short flight", func(f models.Flight) models.Flight { return f }, "business", "business cabin is not allowed"},
+		{"business allowed on long flight", func(f models.Flight) models.Flight { f.Duration = 400; return f }, "business", ""},
+		{"airline not preferred", func(f models.Flight) models.Flight { f.Airline = "Lion Air"; return f }, "economy", "not a preferred airline"},
+		{"booked too late", func(f models.Flight) models.Flight { f.DepartureTime = now.AddDate(0, 0, 3); return f }, "economy", "booked 3 days ahead"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := evaluatePolicy(policy, tt.flight(inPolicy), tt.cabinClass, now)
+			if tt.expectedReason == "" {
+				if !status.InPolicy {
+					t.Errorf("Expected in policy, got reasons %v", status.Reasons)
+				}
+				return
+			}
+			if status.InPolicy || len(status.Reasons) != 1 || !strings.Contains(status.Reasons[0], tt.expectedReason) {
+				t.Errorf("Expected one reason containing %q, got %+v", tt.expectedReason, status)
+			}
+		})
+	}
+}
+
+func TestFlightUsecase_SearchFlights_HideOutOfPolicy(t *testing.T) {
+	usecase := NewFlightUsecase(&mockFlightService{}, nil, nil, nil)
+	req := models.SearchRequest{Origin: "CGK", Destination: "DPS", DepartureDate: "2025-12-15", Passengers: 1, CabinClass: "economy"}
+	tenant := &models.Tenant{ID: "corp", TravelPolicy: &models.TravelPolicy{PreferredAirlines: []string{"Batik Air"}}}
+
+	result, err := usecase.SearchFlightsExpected(context.Background(), req, models.FilterOptions{}, tenant)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(result.Flights) != 1 || result.Flights[0].Policy == nil || result.Flights[0].Policy.InPolicy {
+		t.Fatalf("Expected the Garuda flight annotated as out of policy, got %+v", result.Flights)
+	}
+
+	tenant.TravelPolicy.HideOutOfPolicy = true
+	result, err = usecase.SearchFlightsExpected(context.Background(), req, models.FilterOptions{}, tenant)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(result.Flights) != 0 {
+		t.Errorf("Expected out of policy flights to be hidden, got %d", len(result.Flights))
+	}
+}
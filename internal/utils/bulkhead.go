@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"context"
+	"flight-aggregator/internal/apperror"
+	"time"
+)
+
+// ErrBulkheadFull is returned when no slot frees up within the queue timeout
+var ErrBulkheadFull = apperror.New(apperror.CodeProviderThrottled, "too many concurrent requests")
+
+// Bulkhead limits the number of concurrent calls to a single provider
+type Bulkhead struct {
+	slots        chan struct{}
+	queueTimeout time.Duration
+}
+
+func NewBulkhead(maxConcurrent int, queueTimeout time.Duration) *Bulkhead {
+	if maxConcurrent <= 0 {
+		maxConcurrent = 1
+	}
+	return &Bulkhead{
+		slots:        make(chan struct{}, maxConcurrent),
+		queueTimeout: queueTimeout,
+	}
+}
+
+// Acquire waits up to the queue timeout for a free slot. The returned
+// function must be called to release the slot.
+func (b *Bulkhead) Acquire(ctx context.Context) (func(), error) {
+	release := func() { <-b.slots }
+
+	select {
+	case b.slots <- struct{}{}:
+		return release, nil
+	default:
+	}
+
+	if b.queueTimeout <= 0 {
+		return nil, ErrBulkheadFull
+	}
+
+	timer := time.NewTimer(b.queueTimeout)
+	defer timer.Stop()
+
+	select {
+	case b.slots <- struct{}{}:
+		return release, nil
+	case <-timer.C:
+		return nil, ErrBulkheadFull
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// InFlight returns the number of calls currently holding a slot
+func (b *Bulkhead) InFlight() int {
+	return len(b.slots)
+}
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBulkhead_Acquire(t *testing.T) {
+	bulkhead := NewBulkhead(2, 10*time.Millisecond)
+
+	release1, err := bulkhead.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	release2, err := bulkhead.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Third caller waits for the queue timeout and fails fast
+	if _, err := bulkhead.Acquire(context.Background()); !errors.Is(err, ErrBulkheadFull) {
+		t.Errorf("Expected ErrBulkheadFull, got %v", err)
+	}
+
+	release1()
+	release3, err := bulkhead.Acquire(context.Background())
+	if err != nil {
+		t.Errorf("Expected slot after release, got %v", err)
+	}
+
+	release2()
+	release3()
+	if bulkhead.InFlight() != 0 {
+		t.Errorf("Expected 0 in flight, got %d", bulkhead.InFlight())
+	}
+}
@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultHedgeMinSamples is how many latencies must be observed before hedging starts
+const DefaultHedgeMinSamples = 20
+
+// HedgePolicy describes when a second, hedged call is sent to a provider
+type HedgePolicy struct {
+	Enabled    bool
+	Percentile float64 // hedge once the call is slower than this latency percentile
+	MaxRatio   float64 // hedges allowed per call, e.g. 0.1 = at most 10% extra traffic
+	MinSamples int
+}
+
+type HedgeUtil struct {
+	policy    HedgePolicy
+	histogram *LatencyHistogram
+	budget    *RetryBudget
+}
+
+type hedgeResult struct {
+	value   interface{}
+	err     error
+	latency time.Duration
+}
+
+func NewHedgeUtil(policy HedgePolicy) *HedgeUtil {
+	if policy.MinSamples <= 0 {
+		policy.MinSamples = DefaultHedgeMinSamples
+	}
+	return &HedgeUtil{
+		policy:    policy,
+		histogram: NewLatencyHistogram(DefaultHistogramSize),
+		budget:    NewRetryBudget(policy.MaxRatio, 0),
+	}
+}
+
+// Histogram exposes the recent latencies of the hedged provider
+func (hu *HedgeUtil) Histogram() *LatencyHistogram {
+	return hu.histogram
+}
+
+// Execute runs operation and, if it hasn't returned by the configured latency
+// percentile, starts a second one. The first success wins and the other call
+// is cancelled through its context. The winning call is recorded with the latency
+// the caller saw, measured from the first launch, so a winning hedge doesn't pull
+// the percentile below the provider's real latency. Failures are not recorded: a
+// fast refusal would shorten the hedge delay just when the provider is failing.
+func (hu *HedgeUtil) Execute(ctx context.Context, operation func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	hu.budget.Deposit()
+
+	hedgeCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan hedgeResult, 2)
+	start := time.Now()
+	launch := func() {
+		value, err := operation(hedgeCtx)
+		results <- hedgeResult{value: value, err: err, latency: time.Since(start)}
+	}
+
+	go launch()
+	inflight := 1
+
+	var hedgeTimer <-chan time.Time
+	if delay, ok := hu.hedgeDelay(); ok {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		hedgeTimer = timer.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-hedgeTimer:
+			hedgeTimer = nil
+			if hu.budget.TryWithdraw() {
+				go launch()
+				inflight++
+			}
+		case result := <-results:
+			inflight--
+			if result.err == nil {
+				hu.histogram.Record(result.latency)
+				return result.value, nil
+			}
+			if inflight == 0 {
+				return nil, result.err
+			}
+		}
+	}
+}
+
+// hedgeDelay returns the percentile latency after which a hedge is sent
+func (hu *HedgeUtil) hedgeDelay() (time.Duration, bool) {
+	if !hu.policy.Enabled || hu.histogram.Count() < hu.policy.MinSamples {
+		return 0, false
+	}
+	return hu.histogram.Percentile(hu.policy.Percentile), true
+}
@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLatencyHistogram_Percentile(t *testing.T) {
+	histogram := NewLatencyHistogram(100)
+	for i := 1; i <= 100; i++ {
+		histogram.Record(time.Duration(i) * time.Millisecond)
+	}
+
+	if p50 := histogram.Percentile(50); p50 != 50*time.Millisecond {
+		t.Errorf("Expected p50 50ms, got %v", p50)
+	}
+	if p95 := histogram.Percentile(95); p95 != 95*time.Millisecond {
+		t.Errorf("Expected p95 95ms, got %v", p95)
+	}
+
+	// Oldest samples are overwritten once the window is full
+	histogram.Record(500 * time.Millisecond)
+	if histogram.Count() != 100 {
+		t.Errorf("Expected 100 samples, got %d", histogram.Count())
+	}
+}
+
+func TestHedgeUtil_Execute_HedgeWinsAndCancelsLoser(t *testing.T) {
+	hu := NewHedgeUtil(HedgePolicy{Enabled: true, Percentile: 50, MaxRatio: 1, MinSamples: 1})
+	hu.budget = NewRetryBudget(1, 1)
+	hu.Histogram().Record(10 * time.Millisecond)
+
+	var calls int32
+	var cancelled int32
+	result, err := hu.Execute(context.Background(), func(ctx context.Context) (interface{}, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			// Primary call is slow and must be cancelled by the hedge
+			select {
+			case <-ctx.Done():
+				atomic.AddInt32(&cancelled, 1)
+				return nil, ctx.Err()
+			case <-time.After(time.Second):
+				return "primary", nil
+			}
+		}
+		return "hedge", nil
+	})
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result != "hedge" {
+		t.Errorf("Expected hedge result, got %v", result)
+	}
+	if atomic.LoadInt32(&calls) != 2 {
+		t.Errorf("Expected 2 calls, got %d", calls)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if atomic.LoadInt32(&cancelled) != 1 {
+		t.Error("Expected losing call to be cancelled")
+	}
+}
+
+func TestHedgeUtil_Execute_RecordsLatencyFromFirstLaunch(t *testing.T) {
+	hu := NewHedgeUtil(HedgePolicy{Enabled: true, Percentile: 50, MaxRatio: 1, MinSamples: 1})
+	hu.budget = NewRetryBudget(1, 1)
+	hu.Histogram().Record(20 * time.Millisecond)
+
+	var calls int32
+	_, err := hu.Execute(context.Background(), func(ctx context.Context) (interface{}, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return "hedge", nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The hedge answers at once but the caller waited for the hedge delay first
+	if hu.Histogram().Count() != 2 {
+		t.Fatalf("Expected 2 samples, got %d", hu.Histogram().Count())
+	}
+	if fastest := hu.Histogram().Percentile(0); fastest < 20*time.Millisecond {
+		t.Errorf("Expected the hedge recorded from the first launch, got %v", fastest)
+	}
+}
+
+func TestHedgeUtil_Execute_Disabled(t *testing.T) {
+	hu := NewHedgeUtil(HedgePolicy{Enabled: false})
+
+	var calls int32
+	_, err := hu.Execute(context.Background(), func(ctx context.Context) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		return nil, errors.New("provider error")
+	})
+
+	if err == nil {
+		t.Error("Expected error but got none")
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 call without hedging, got %d", calls)
+	}
+	if hu.Histogram().Count() != 0 {
+		t.Errorf("Expected the failed call not to be recorded, got %d samples", hu.Histogram().Count())
+	}
+}
@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultHistogramSize is the number of recent samples kept per provider
+const DefaultHistogramSize = 200
+
+// LatencyHistogram keeps a sliding window of the most recent call latencies
+type LatencyHistogram struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+	full    bool
+}
+
+func NewLatencyHistogram(size int) *LatencyHistogram {
+	if size <= 0 {
+		size = DefaultHistogramSize
+	}
+	return &LatencyHistogram{samples: make([]time.Duration, size)}
+}
+
+// Record adds a latency sample, overwriting the oldest one when the window is full
+func (lh *LatencyHistogram) Record(latency time.Duration) {
+	lh.mu.Lock()
+	defer lh.mu.Unlock()
+
+	lh.samples[lh.next] = latency
+	lh.next = (lh.next + 1) % len(lh.samples)
+	if lh.next == 0 {
+		lh.full = true
+	}
+}
+
+// Count returns the number of samples currently in the window
+func (lh *LatencyHistogram) Count() int {
+	lh.mu.Lock()
+	defer lh.mu.Unlock()
+
+	if lh.full {
+		return len(lh.samples)
+	}
+	return lh.next
+}
+
+// Percentile returns the latency at percentile p (0-100) using nearest rank
+func (lh *LatencyHistogram) Percentile(p float64) time.Duration {
+	lh.mu.Lock()
+	count := lh.next
+	if lh.full {
+		count = len(lh.samples)
+	}
+	sorted := make([]time.Duration, count)
+	copy(sorted, lh.samples[:count])
+	lh.mu.Unlock()
+
+	if count == 0 {
+		return 0
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+
+	rank := int(math.Ceil(p/100*float64(count))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= count {
+		rank = count - 1
+	}
+	return sorted[rank]
+}
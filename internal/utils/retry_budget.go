@@ -0,0 +1,55 @@
+package utils
+
+import "sync"
+
+// RetryBudget caps retries as a fraction of overall traffic. Every first attempt
+// deposits ratio tokens and every retry withdraws one, so during an outage the
+// retry volume stays bounded by ratio * request volume instead of multiplying it.
+type RetryBudget struct {
+	mu        sync.Mutex
+	ratio     float64
+	tokens    float64
+	maxTokens float64
+}
+
+// budgetHeadroomRequests is how many requests' worth of retry tokens the budget can
+// bank on top of minRetries, so a burst of failures after a quiet period is not
+// starved while a long healthy period cannot build an unbounded retry storm
+const budgetHeadroomRequests = 100
+
+// NewRetryBudget creates a budget that allows ratio retries per request.
+// minRetries is the initial reserve so low-traffic periods can still retry.
+func NewRetryBudget(ratio float64, minRetries int) *RetryBudget {
+	maxTokens := float64(minRetries)
+	if maxTokens < 1 {
+		maxTokens = 1
+	}
+	return &RetryBudget{
+		ratio:     ratio,
+		tokens:    float64(minRetries),
+		maxTokens: maxTokens + ratio*budgetHeadroomRequests,
+	}
+}
+
+// Deposit records a first attempt
+func (rb *RetryBudget) Deposit() {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	rb.tokens += rb.ratio
+	if rb.tokens > rb.maxTokens {
+		rb.tokens = rb.maxTokens
+	}
+}
+
+// TryWithdraw reports whether a retry is allowed and consumes it
+func (rb *RetryBudget) TryWithdraw() bool {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	if rb.tokens < 1 {
+		return false
+	}
+	rb.tokens--
+	return true
+}
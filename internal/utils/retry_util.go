@@ -2,13 +2,28 @@ package utils
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"flight-aggregator/internal/apperror"
 	"math"
+	"math/rand"
+	"net"
+	"net/http"
 	"time"
 )
 
+// RetryPolicy describes how a single provider call is retried
+type RetryPolicy struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
 type RetryUtil struct {
 	maxRetries int
 	baseDelay  time.Duration
+	maxDelay   time.Duration
+	budget     *RetryBudget
 }
 
 func NewRetryUtil(maxRetries int, baseDelay time.Duration) *RetryUtil {
@@ -18,32 +33,155 @@ func NewRetryUtil(maxRetries int, baseDelay time.Duration) *RetryUtil {
 	}
 }
 
+// NewRetryUtilWithPolicy creates a RetryUtil that draws retries from a shared budget.
+// A nil budget means retries are only limited by the policy.
+func NewRetryUtilWithPolicy(policy RetryPolicy, budget *RetryBudget) *RetryUtil {
+	return &RetryUtil{
+		maxRetries: policy.MaxRetries,
+		baseDelay:  policy.BaseDelay,
+		maxDelay:   policy.MaxDelay,
+		budget:     budget,
+	}
+}
+
 func (ru *RetryUtil) ExecuteWithRetry(ctx context.Context, operation func() error) error {
+	_, err := ru.ExecuteWithRetryAttempts(ctx, operation)
+	return err
+}
+
+// ExecuteWithRetryAttempts behaves like ExecuteWithRetry and also reports how many
+// times the operation was invoked
+func (ru *RetryUtil) ExecuteWithRetryAttempts(ctx context.Context, operation func() error) (int, error) {
 	var lastErr error
-	
+	attempts := 0
+
+	if ru.budget != nil {
+		ru.budget.Deposit()
+	}
+
 	for attempt := 0; attempt <= ru.maxRetries; attempt++ {
 		if attempt > 0 {
+			if !IsRetryable(lastErr) {
+				return attempts, lastErr
+			}
+			if ru.budget != nil && !ru.budget.TryWithdraw() {
+				return attempts, lastErr
+			}
+
 			delay := ru.calculateDelay(attempt)
+			if retryAfter := RetryAfter(lastErr); retryAfter > delay {
+				delay = retryAfter
+			}
 			select {
 			case <-ctx.Done():
-				return ctx.Err()
+				return attempts, ctx.Err()
 			case <-time.After(delay):
 			}
 		}
-		
+
+		attempts++
 		if err := operation(); err != nil {
 			lastErr = err
 			continue
 		}
-		
-		return nil
+
+		return attempts, nil
 	}
-	
-	return lastErr
+
+	return attempts, lastErr
 }
 
+// calculateDelay applies full jitter: a random delay between zero and the
+// exponential backoff ceiling baseDelay * 2^(attempt-1), capped at maxDelay
 func (ru *RetryUtil) calculateDelay(attempt int) time.Duration {
-	// Exponential backoff: baseDelay * 2^(attempt-1)
-	delay := float64(ru.baseDelay) * math.Pow(2, float64(attempt-1))
-	return time.Duration(delay)
-}
\ No newline at end of file
+	ceiling := float64(ru.baseDelay) * math.Pow(2, float64(attempt-1))
+	if ru.maxDelay > 0 && ceiling > float64(ru.maxDelay) {
+		ceiling = float64(ru.maxDelay)
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(ceiling) + 1))
+}
+
+// StatusError carries the upstream HTTP status of a failed provider call
+type StatusError struct {
+	StatusCode int
+	RetryAfter time.Duration
+	Message    string
+}
+
+func (e *StatusError) Error() string {
+	return e.Message
+}
+
+// PermanentError marks an error that will never succeed on retry
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// Permanent wraps err so the retry loop stops immediately
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PermanentError{Err: err}
+}
+
+// IsRetryable separates transient failures (timeouts, 5xx, 429) from permanent ones
+// (parse failures, 4xx, cancellation, a full bulkhead). Typed errors follow their
+// catalog code. Unclassified errors are treated as transient.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var permanentErr *PermanentError
+	if errors.As(err, &permanentErr) {
+		return false
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+		return false
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+
+	var appErr *apperror.Error
+	if errors.As(err, &appErr) {
+		return appErr.Retryable
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return netErr.Timeout()
+	}
+
+	return true
+}
+
+// RetryAfter returns the delay requested by a 429 response, or zero
+func RetryAfter(err error) time.Duration {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusTooManyRequests {
+		return statusErr.RetryAfter
+	}
+	return 0
+}
@@ -3,6 +3,7 @@ package utils
 import (
 	"context"
 	"errors"
+	"flight-aggregator/internal/apperror"
 	"testing"
 	"time"
 )
@@ -80,4 +81,75 @@ func TestRetryUtil_ExecuteWithRetry_ContextCancellation(t *testing.T) {
 	if err == nil {
 		t.Error("Expected context cancellation error")
 	}
-}
\ No newline at end of file
+}
+
+func TestRetryUtil_ExecuteWithRetry_PermanentError(t *testing.T) {
+	ru := NewRetryUtil(3, time.Millisecond)
+
+	attempts := 0
+	err := ru.ExecuteWithRetry(context.Background(), func() error {
+		attempts++
+		return Permanent(errors.New("invalid response"))
+	})
+
+	if err == nil {
+		t.Error("Expected error but got none")
+	}
+	if attempts != 1 {
+		t.Errorf("Expected 1 attempt for permanent error, got %d", attempts)
+	}
+}
+
+func TestRetryUtil_ExecuteWithRetry_BudgetExhausted(t *testing.T) {
+	budget := NewRetryBudget(0, 1)
+	ru := NewRetryUtilWithPolicy(RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond}, budget)
+
+	attempts := 0
+	ru.ExecuteWithRetry(context.Background(), func() error {
+		attempts++
+		return &StatusError{StatusCode: 503, Message: "unavailable"}
+	})
+
+	// One initial attempt plus the single retry the budget allows
+	if attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", attempts)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"timeout", context.DeadlineExceeded, true},
+		{"server error", &StatusError{StatusCode: 503}, true},
+		{"too many requests", &StatusError{StatusCode: 429, RetryAfter: time.Second}, true},
+		{"bad request", &StatusError{StatusCode: 400}, false},
+		{"permanent", Permanent(errors.New("parse failure")), false},
+		{"canceled", context.Canceled, false},
+		{"unclassified", errors.New("temporary failure"), true},
+		{"bulkhead full", ErrBulkheadFull, false},
+		{"typed retryable", apperror.New(apperror.CodeProvider, "service unavailable"), true},
+		{"typed permanent", apperror.New(apperror.CodeValidation, ""), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.expected {
+				t.Errorf("IsRetryable() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRetryUtil_CalculateDelay_FullJitter(t *testing.T) {
+	ru := NewRetryUtilWithPolicy(RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}, nil)
+
+	for attempt := 1; attempt <= 5; attempt++ {
+		delay := ru.calculateDelay(attempt)
+		if delay < 0 || delay > 300*time.Millisecond {
+			t.Errorf("Delay %v for attempt %d outside [0, 300ms]", delay, attempt)
+		}
+	}
+}
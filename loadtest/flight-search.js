@@ -11,11 +11,14 @@ export let options = {
 
 const BASE_URL = 'http://localhost:8080';
 
+// Searches in the past are rejected, so always search a month ahead
+const DEPARTURE_DATE = new Date(Date.now() + 30 * 24 * 60 * 60 * 1000).toISOString().slice(0, 10);
+
 export default function () {
   const payload = JSON.stringify({
     origin: 'CGK',
     destination: 'DPS',
-    departureDate: '2025-12-15',
+    departureDate: DEPARTURE_DATE,
     passengers: 1,
     cabinClass: 'economy'
   });